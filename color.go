@@ -103,16 +103,120 @@ func (c *RGBA) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&s)
 }
 
+// GradientKind defines the shape of a Gradient.
+type GradientKind int
+
+const (
+	// NoGradient disables the gradient.
+	NoGradient GradientKind = iota
+	// LinearGradient changes color along a straight line.
+	LinearGradient
+	// RadialGradient changes color from the center to the corners.
+	RadialGradient
+	// NumGradientKinds is not a valid GradientKind, but used for iteration.
+	NumGradientKinds
+)
+
+// GradientStop defines the color of a gradient at a certain offset.
+type GradientStop struct {
+	// Offset is the position of the stop on the gradient, between 0.0 (the
+	// gradient's start) and 1.0 (its end).
+	Offset float32 `json:"offset" yaml:"offset"`
+	Color  RGBA    `json:"color" yaml:"color"`
+}
+
+// MaxGradientStops is the maximum number of stops a Gradient can have.
+const MaxGradientStops = 8
+
+// Gradient describes a smooth transition between multiple colors.
+//
+// The color between two stops is interpolated, before the first stop and
+// after the last stop the color of that stop is used. Stops should be ordered
+// by their offset.
+//
+// The stops are stored in a fixed-size array so that Gradient, and thus
+// Background, stays comparable. Use SetStops and UsedStops to access them.
+type Gradient struct {
+	Kind GradientKind
+	// Angle is the direction of a LinearGradient in degrees, counterclockwise.
+	// At 0, the gradient starts at the left and ends at the right edge; at 90,
+	// it starts at the bottom and ends at the top edge.
+	//
+	// A RadialGradient starts at the center and ends at the corners; it
+	// ignores the angle.
+	Angle float32
+	// Stops contains the gradient's stops; only the first NumStops are used.
+	// Unused stops should be zero so that equal gradients compare equal.
+	Stops    [MaxGradientStops]GradientStop
+	NumStops int
+}
+
+// SetStops replaces the gradient's stops with the given ones. Returns an
+// error if there are more than MaxGradientStops stops.
+func (g *Gradient) SetStops(stops ...GradientStop) error {
+	if len(stops) > MaxGradientStops {
+		return fmt.Errorf("gradient has more than %d stops", MaxGradientStops)
+	}
+	g.Stops = [MaxGradientStops]GradientStop{}
+	g.NumStops = copy(g.Stops[:], stops)
+	return nil
+}
+
+// UsedStops returns a copy of the stops of the gradient that are in use.
+func (g Gradient) UsedStops() []GradientStop {
+	if g.NumStops < 0 || g.NumStops > MaxGradientStops {
+		return nil
+	}
+	return g.Stops[:g.NumStops]
+}
+
+// Active returns true iff the gradient should be drawn.
+func (g Gradient) Active() bool {
+	return g.Kind > NoGradient && g.Kind < NumGradientKinds &&
+		len(g.UsedStops()) > 0
+}
+
+// gradientData is the serialized form of Gradient, listing only the used
+// stops.
+type gradientData struct {
+	Kind  GradientKind   `json:"kind" yaml:"kind"`
+	Angle float32        `json:"angle" yaml:"angle"`
+	Stops []GradientStop `json:"stops" yaml:"stops"`
+}
+
+func (g *Gradient) fromData(data gradientData) error {
+	g.Kind, g.Angle = data.Kind, data.Angle
+	return g.SetStops(data.Stops...)
+}
+
+// MarshalJSON writes the gradient with a list of its used stops.
+func (g Gradient) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&gradientData{Kind: g.Kind, Angle: g.Angle,
+		Stops: g.UsedStops()})
+}
+
+// UnmarshalJSON loads a gradient written by MarshalJSON.
+func (g *Gradient) UnmarshalJSON(data []byte) error {
+	var tmp gradientData
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	return g.fromData(tmp)
+}
+
 // Background describes how the background of a rectangle should be colored.
 // It defines a primary and secondary color and optionally the index of a
 // texture that should be used to merge the colors.
 //
 // If not texture is specified, the background is drawn using only the Primary
 // color.
+//
+// If an active Gradient is given, it is used instead of the Primary color.
 type Background struct {
-	Primary      RGBA `json:"primary"`
-	Secondary    RGBA `json:"secondary"`
-	TextureIndex int  `json:"textureIndex"`
+	Primary      RGBA     `json:"primary"`
+	Secondary    RGBA     `json:"secondary"`
+	TextureIndex int      `json:"textureIndex"`
+	Gradient     Gradient `json:"gradient"`
 }
 
 // AsBackground returns a Background with c as background color and no texture.
@@ -40,6 +40,28 @@ func (vi *ValidatedInt) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ValidatedFloat can be used to load a floating-point value that must be in a
+// specified range.
+type ValidatedFloat struct {
+	// data is loaded into this
+	Value float32
+	// inclusive required range
+	Min, Max float32
+}
+
+// UnmarshalJSON loads the given JSON input as float value and on success
+// checks whether the loaded value is inside the required range.
+func (vf *ValidatedFloat) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &vf.Value); err != nil {
+		return err
+	}
+	if vf.Value < vf.Min || vf.Value > vf.Max {
+		return fmt.Errorf("value outside of allowed range [%g..%g]",
+			vf.Min, vf.Max)
+	}
+	return nil
+}
+
 // ValidatedString can be used to load a string value whose length must be in a
 // specified range.
 type ValidatedString struct {
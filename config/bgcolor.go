@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/QuestScreen/api"
 	"github.com/QuestScreen/api/comms"
@@ -10,7 +11,7 @@ import (
 
 // BackgroundSelect is an Item that allows the user to define a background
 // by setting a primary color and optionally, a secondary color together with a
-// texture.
+// texture, and a gradient that replaces the primary color.
 type BackgroundSelect struct {
 	api.Background
 }
@@ -23,8 +24,34 @@ func NewBackgroundSelect(value api.Background) *BackgroundSelect {
 	return &BackgroundSelect{Background: value}
 }
 
+// validateGradient checks that the given gradient has a known kind, an angle
+// within [-360..360] and at most api.MaxGradientStops stops with offsets
+// within [0..1].
+func validateGradient(g api.Gradient) error {
+	if g.Kind < api.NoGradient || g.Kind >= api.NumGradientKinds {
+		return fmt.Errorf("unknown gradient kind: %d", g.Kind)
+	}
+	if err := checkRange("gradient angle", g.Angle, -360, 360); err != nil {
+		return err
+	}
+	if g.NumStops < 0 || g.NumStops > api.MaxGradientStops {
+		return fmt.Errorf("gradient stops outside of supported length [0..%d]",
+			api.MaxGradientStops)
+	}
+	for _, stop := range g.UsedStops() {
+		if err := checkRange("gradient stop offset", stop.Offset, 0, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Receive loads a background from a json input
-// `{"primary": <rgb>, "secondary": <rgb>, "textureIndex": <number>}`
+// `{"primary": <rgb>, "secondary": <rgb>, "textureIndex": <number>,
+// "gradient": {"kind": <number>, "angle": <number>,
+// "stops": [{"offset": <number>, "color": <rgba>}, ...]}}`
+//
+// The gradient is optional.
 func (b *BackgroundSelect) Receive(
 	input json.RawMessage, ctx server.Context) error {
 	textures := ctx.GetTextures()
@@ -32,18 +59,24 @@ func (b *BackgroundSelect) Receive(
 		Primary      api.RGBA           `json:"primary"`
 		Secondary    api.RGBA           `json:"secondary"`
 		TextureIndex comms.ValidatedInt `json:"textureIndex"`
+		Gradient     api.Gradient       `json:"gradient"`
 	}{TextureIndex: comms.ValidatedInt{Min: -1, Max: len(textures) - 1}}
 	if err := comms.ReceiveData(input, &value); err != nil {
 		return err
 	}
+	if err := validateGradient(value.Gradient); err != nil {
+		return err
+	}
 	b.Background = api.Background{Primary: value.Primary,
-		Secondary: value.Secondary, TextureIndex: value.TextureIndex.Value}
+		Secondary: value.Secondary, TextureIndex: value.TextureIndex.Value,
+		Gradient: value.Gradient}
 	return nil
 }
 
 type persistedBackground struct {
 	Primary, Secondary api.RGBA
 	Texture            string
+	Gradient           *api.Gradient `yaml:",omitempty"`
 }
 
 // Send returns the object itself.
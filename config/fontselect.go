@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/QuestScreen/api"
 	"github.com/QuestScreen/api/comms"
@@ -14,14 +15,69 @@ type FontSelect struct {
 	api.Font
 }
 
+type webOutline struct {
+	Width comms.ValidatedFloat `json:"width"`
+	Color api.RGBA             `json:"color"`
+}
+
+type webShadow struct {
+	OffsetX comms.ValidatedFloat `json:"offsetX"`
+	OffsetY comms.ValidatedFloat `json:"offsetY"`
+	Color   api.RGBA             `json:"color"`
+}
+
+type webGlow struct {
+	Radius comms.ValidatedFloat `json:"radius"`
+	Color  api.RGBA             `json:"color"`
+}
+
 type webFont struct {
 	FamilyIndex comms.ValidatedInt `json:"familyIndex"`
 	Size        comms.ValidatedInt `json:"size"`
 	Style       comms.ValidatedInt `json:"style"`
 	Color       api.RGBA           `json:"color"`
+	Outline     webOutline         `json:"outline"`
+	Shadow      webShadow          `json:"shadow"`
+	Glow        webGlow            `json:"glow"`
+}
+
+// maximum lengths of text effects, in units.
+const (
+	maxOutlineWidth = 2
+	maxShadowOffset = 4
+	maxGlowRadius   = 4
+)
+
+// checkRange returns an error if the named value lies outside of [min, max].
+func checkRange(name string, value, min, max float32) error {
+	if !(value >= min && value <= max) {
+		return fmt.Errorf("%s outside of allowed range [%g..%g]", name, min, max)
+	}
+	return nil
+}
+
+// validateEffects checks that the text effects of the given font lie within
+// the limits Receive enforces.
+func validateEffects(f api.Font) error {
+	for _, v := range [...]struct {
+		name            string
+		value, min, max float32
+	}{
+		{"outline width", f.Outline.Width, 0, maxOutlineWidth},
+		{"shadow offsetX", f.Shadow.OffsetX, -maxShadowOffset, maxShadowOffset},
+		{"shadow offsetY", f.Shadow.OffsetY, -maxShadowOffset, maxShadowOffset},
+		{"glow radius", f.Glow.Radius, 0, maxGlowRadius},
+	} {
+		if err := checkRange(v.name, v.value, v.min, v.max); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// NewFontSelect creates a new FontSelect item with the given values
+// NewFontSelect creates a new FontSelect item with the given values.
+// Text effects are disabled and can be enabled by setting the respective
+// fields of the returned object.
 func NewFontSelect(familyIndex int, size api.FontSize, style api.FontStyle,
 	color api.RGBA) *FontSelect {
 	return &FontSelect{Font: api.Font{FamilyIndex: familyIndex, Size: size,
@@ -29,13 +85,24 @@ func NewFontSelect(familyIndex int, size api.FontSize, style api.FontStyle,
 }
 
 // Receive loads a font from a json input
-// `{"familyIndex": <number>, "size": <number>, "style": <number>}`
+// `{"familyIndex": <number>, "size": <number>, "style": <number>,
+// "color": <rgba>, "outline": {"width": <number>, "color": <rgba>},
+// "shadow": {"offsetX": <number>, "offsetY": <number>, "color": <rgba>},
+// "glow": {"radius": <number>, "color": <rgba>}}`
+//
+// The effects are optional; missing effects are disabled.
 func (f *FontSelect) Receive(
 	input json.RawMessage, ctx server.Context) error {
 	tmp := webFont{
 		FamilyIndex: comms.ValidatedInt{Min: 0, Max: ctx.NumFontFamilies() - 1},
 		Size:        comms.ValidatedInt{Min: 0, Max: int(api.HugeFont)},
 		Style:       comms.ValidatedInt{Min: 0, Max: int(api.BoldItalicFont)},
+		Outline: webOutline{
+			Width: comms.ValidatedFloat{Min: 0, Max: maxOutlineWidth}},
+		Shadow: webShadow{
+			OffsetX: comms.ValidatedFloat{Min: -maxShadowOffset, Max: maxShadowOffset},
+			OffsetY: comms.ValidatedFloat{Min: -maxShadowOffset, Max: maxShadowOffset}},
+		Glow: webGlow{Radius: comms.ValidatedFloat{Min: 0, Max: maxGlowRadius}},
 	}
 	if err := comms.ReceiveData(input, &tmp); err != nil {
 		return err
@@ -43,7 +110,12 @@ func (f *FontSelect) Receive(
 	f.Font = api.Font{FamilyIndex: tmp.FamilyIndex.Value,
 		Size:  api.FontSize(tmp.Size.Value),
 		Style: api.FontStyle(tmp.Style.Value),
-		Color: tmp.Color}
+		Color: tmp.Color,
+		Outline: api.TextOutline{Width: tmp.Outline.Width.Value,
+			Color: tmp.Outline.Color},
+		Shadow: api.TextShadow{OffsetX: tmp.Shadow.OffsetX.Value,
+			OffsetY: tmp.Shadow.OffsetY.Value, Color: tmp.Shadow.Color},
+		Glow: api.TextGlow{Radius: tmp.Glow.Radius.Value, Color: tmp.Glow.Color}}
 	return nil
 }
 
@@ -11,23 +11,39 @@ import (
 )
 
 type persistedFont struct {
-	Family string        `yaml:"family"`
-	Size   api.FontSize  `yaml:"size"`
-	Style  api.FontStyle `yaml:"style"`
-	Color  api.RGBA      `yaml:"color"`
+	Family  string          `yaml:"family"`
+	Size    api.FontSize    `yaml:"size"`
+	Style   api.FontStyle   `yaml:"style"`
+	Color   api.RGBA        `yaml:"color"`
+	Outline api.TextOutline `yaml:"outline,omitempty"`
+	Shadow  api.TextShadow  `yaml:"shadow,omitempty"`
+	Glow    api.TextGlow    `yaml:"glow,omitempty"`
 }
 
 // Load loads a selectable font from a YAML input
-// `{family: <string>, size: <number>, style: <number>}`
+// `{family: <string>, size: <number>, style: <number>, color: <rgba>,
+// outline: {width: <number>, color: <rgba>},
+// shadow: {offsetX: <number>, offsetY: <number>, color: <rgba>},
+// glow: {radius: <number>, color: <rgba>}}`
+//
+// The effects are optional; missing effects are disabled. Their values must
+// be within the same limits Receive enforces.
 func (f *FontSelect) Load(
 	input *yaml.Node, ctx server.Context) error {
 	var tmp persistedFont
 	if err := input.Decode(&tmp); err != nil {
 		return err
 	}
+	if err := validateEffects(api.Font{Outline: tmp.Outline, Shadow: tmp.Shadow,
+		Glow: tmp.Glow}); err != nil {
+		return err
+	}
 	f.Size = tmp.Size
 	f.Style = tmp.Style
 	f.Color = tmp.Color
+	f.Outline = tmp.Outline
+	f.Shadow = tmp.Shadow
+	f.Glow = tmp.Glow
 	for i := 0; i < ctx.NumFontFamilies(); i++ {
 		if tmp.Family == ctx.FontFamilyName(i) {
 			f.FamilyIndex = i
@@ -42,23 +58,39 @@ func (f *FontSelect) Load(
 // Persist returns a view that gives the family name as string.
 func (f *FontSelect) Persist(ctx server.Context) interface{} {
 	return &persistedFont{
-		Family: ctx.FontFamilyName(f.FamilyIndex),
-		Size:   f.Size,
-		Style:  f.Style,
-		Color:  f.Color,
+		Family:  ctx.FontFamilyName(f.FamilyIndex),
+		Size:    f.Size,
+		Style:   f.Style,
+		Color:   f.Color,
+		Outline: f.Outline,
+		Shadow:  f.Shadow,
+		Glow:    f.Glow,
 	}
 }
 
 // Load loads a background from a YAML input
-// `{primary: <rgb>, secondary: <rgb>, texture: <name>}`
+// `{primary: <rgb>, secondary: <rgb>, texture: <name>,
+// gradient: {kind: <None|Linear|Radial>, angle: <number>,
+// stops: [{offset: <number>, color: <rgba>}, ...]}}`
+//
+// The gradient is optional. If given, it must be within the same limits
+// Receive enforces.
 func (b *BackgroundSelect) Load(
 	input *yaml.Node, ctx server.Context) error {
 	var value persistedBackground
 	if err := input.Decode(&value); err != nil {
 		return err
 	}
+	var gradient api.Gradient
+	if value.Gradient != nil {
+		gradient = *value.Gradient
+		if err := validateGradient(gradient); err != nil {
+			return err
+		}
+	}
 	b.Primary = value.Primary
 	b.Secondary = value.Secondary
+	b.Gradient = gradient
 	b.TextureIndex = -1
 	if value.Texture != "" {
 		textures := ctx.GetTextures()
@@ -80,6 +112,9 @@ func (b *BackgroundSelect) Persist(ctx server.Context) interface{} {
 	ret := &persistedBackground{
 		Primary: b.Primary, Secondary: b.Secondary,
 	}
+	if b.Gradient.Kind != api.NoGradient {
+		ret.Gradient = &b.Gradient
+	}
 	if b.TextureIndex != -1 {
 		ret.Texture = ctx.GetTextures()[b.TextureIndex].Name
 	}
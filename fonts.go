@@ -38,10 +38,45 @@ const (
 	NumFontSizes
 )
 
+// TextOutline describes an outline drawn around each glyph of a text.
+// Lengths are given in units (see render.Renderer.Unit).
+//
+// The outline is disabled if Color has an alpha value of 0.
+type TextOutline struct {
+	Width float32 `json:"width" yaml:"width"`
+	Color RGBA    `json:"color" yaml:"color"`
+}
+
+// TextShadow describes a drop shadow drawn behind a text.
+// Offsets are given in units (see render.Renderer.Unit); like screen
+// coordinates, a positive OffsetY moves the shadow upwards.
+//
+// The shadow is disabled if Color has an alpha value of 0.
+type TextShadow struct {
+	OffsetX float32 `json:"offsetX" yaml:"offsetX"`
+	OffsetY float32 `json:"offsetY" yaml:"offsetY"`
+	Color   RGBA    `json:"color" yaml:"color"`
+}
+
+// TextGlow describes a soft glow drawn behind a text.
+// Radius is given in units (see render.Renderer.Unit).
+//
+// The glow is disabled if Color has an alpha value of 0.
+type TextGlow struct {
+	Radius float32 `json:"radius" yaml:"radius"`
+	Color  RGBA    `json:"color" yaml:"color"`
+}
+
 // Font describes the font used for drawing text.
+//
+// Outline, Shadow and Glow are optional effects that improve legibility of
+// text drawn on top of busy backgrounds. Their zero values disable them.
 type Font struct {
-	FamilyIndex int       `json:"familyIndex"`
-	Size        FontSize  `json:"size"`
-	Style       FontStyle `json:"style"`
-	Color       RGBA      `json:"color"`
+	FamilyIndex int         `json:"familyIndex"`
+	Size        FontSize    `json:"size"`
+	Style       FontStyle   `json:"style"`
+	Color       RGBA        `json:"color"`
+	Outline     TextOutline `json:"outline"`
+	Shadow      TextShadow  `json:"shadow"`
+	Glow        TextGlow    `json:"glow"`
 }
@@ -5,6 +5,7 @@ go 1.12
 require (
 	github.com/flyx/askew v0.0.0-20210428171302-fc19674d334f
 	github.com/kr/pretty v0.1.0 // indirect
+	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
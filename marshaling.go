@@ -89,3 +89,51 @@ func (fs FontSize) MarshalYAML() (interface{}, error) {
 		return nil, fmt.Errorf("unknown font size: %v", fs)
 	}
 }
+
+// UnmarshalYAML sets the gradient kind from a YAML scalar
+func (gk *GradientKind) UnmarshalYAML(value *yaml.Node) error {
+	var name string
+	if err := value.Decode(&name); err != nil {
+		return err
+	}
+	switch name {
+	case "None":
+		*gk = NoGradient
+	case "Linear":
+		*gk = LinearGradient
+	case "Radial":
+		*gk = RadialGradient
+	default:
+		return fmt.Errorf("unknown gradient kind: %s", name)
+	}
+	return nil
+}
+
+// MarshalYAML maps the given gradient kind to a string
+func (gk GradientKind) MarshalYAML() (interface{}, error) {
+	switch gk {
+	case NoGradient:
+		return "None", nil
+	case LinearGradient:
+		return "Linear", nil
+	case RadialGradient:
+		return "Radial", nil
+	default:
+		return nil, fmt.Errorf("unknown gradient kind: %v", gk)
+	}
+}
+
+// UnmarshalYAML loads a gradient with a list of its stops
+func (g *Gradient) UnmarshalYAML(value *yaml.Node) error {
+	var tmp gradientData
+	if err := value.Decode(&tmp); err != nil {
+		return err
+	}
+	return g.fromData(tmp)
+}
+
+// MarshalYAML writes the gradient with a list of its used stops
+func (g Gradient) MarshalYAML() (interface{}, error) {
+	return &gradientData{Kind: g.Kind, Angle: g.Angle, Stops: g.UsedStops()},
+		nil
+}
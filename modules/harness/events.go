@@ -0,0 +1,176 @@
+package harness
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/QuestScreen/api/modules"
+)
+
+// EventKind describes a call the Harness issued to a module's Renderer.
+type EventKind int
+
+const (
+	// Rebuild is a call to Renderer.Rebuild
+	Rebuild EventKind = iota
+	// InitTransition is a call to Renderer.InitTransition
+	InitTransition
+	// TransitionStep is a call to Renderer.TransitionStep
+	TransitionStep
+	// FinishTransition is a call to Renderer.FinishTransition
+	FinishTransition
+	// Render is a call to Renderer.Render
+	Render
+	// IdleStep is a call to IdleAnimator.IdleStep
+	IdleStep
+	// InterruptTransition is a call to TransitionInterrupter.InterruptTransition
+	InterruptTransition
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Rebuild:
+		return "Rebuild"
+	case InitTransition:
+		return "InitTransition"
+	case TransitionStep:
+		return "TransitionStep"
+	case FinishTransition:
+		return "FinishTransition"
+	case Render:
+		return "Render"
+	case IdleStep:
+		return "IdleStep"
+	case InterruptTransition:
+		return "InterruptTransition"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+func interruptionName(i modules.Interruption) string {
+	switch i {
+	case modules.JumpToEnd:
+		return "JumpToEnd"
+	case modules.Queue:
+		return "Queue"
+	case modules.Merge:
+		return "Merge"
+	default:
+		return fmt.Sprintf("Interruption(%d)", int(i))
+	}
+}
+
+// Event is a recorded call to a module's Renderer.
+type Event struct {
+	Kind EventKind
+	// Time is the time of the harness' clock when the call was issued.
+	Time time.Duration
+	// Duration is the value returned by InitTransition or
+	// InterruptTransition.
+	Duration time.Duration
+	// Elapsed is the value given to TransitionStep or InterruptTransition.
+	Elapsed time.Duration
+	// Interruption is the value returned by InterruptTransition.
+	Interruption modules.Interruption
+	// Delta is the value given to IdleStep.
+	Delta time.Duration
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case InitTransition:
+		return fmt.Sprintf("%v %v -> %v", e.Time, e.Kind, e.Duration)
+	case TransitionStep:
+		return fmt.Sprintf("%v %v(%v)", e.Time, e.Kind, e.Elapsed)
+	case IdleStep:
+		return fmt.Sprintf("%v %v(%v)", e.Time, e.Kind, e.Delta)
+	case InterruptTransition:
+		if e.Interruption == modules.Merge {
+			return fmt.Sprintf("%v %v(%v) -> Merge %v", e.Time, e.Kind, e.Elapsed,
+				e.Duration)
+		}
+		return fmt.Sprintf("%v %v(%v) -> %v", e.Time, e.Kind, e.Elapsed,
+			interruptionName(e.Interruption))
+	default:
+		return fmt.Sprintf("%v %v", e.Time, e.Kind)
+	}
+}
+
+// Verify checks whether the given sequence of events adheres to the ordering
+// guarantees documented on modules.Renderer:
+//
+//   - Rebuild, TransitionStep, FinishTransition and IdleStep are immediately
+//     followed by Render.
+//   - TransitionStep is only called during a transition with a positive
+//     duration, and the elapsed time is smaller than that duration.
+//   - FinishTransition is called exactly once for each InitTransition that
+//     returned a non-negative value, and never for the others.
+//   - IdleStep is never called during a transition.
+//   - InterruptTransition is only called during a transition. If it merges,
+//     the transition's duration is updated to the returned value.
+//
+// The first violation found is returned as error. A transition that is still
+// in progress at the end of the sequence is not considered a violation.
+func Verify(events []Event) error {
+	needsRender := false
+	active := false
+	var duration time.Duration
+	for i, e := range events {
+		if needsRender && e.Kind != Render {
+			return fmt.Errorf("event %d (%v): expected Render after %v", i, e,
+				events[i-1].Kind)
+		}
+		needsRender = false
+		switch e.Kind {
+		case Rebuild:
+			if active {
+				return fmt.Errorf("event %d (%v): Rebuild during transition", i, e)
+			}
+			needsRender = true
+		case InitTransition:
+			if active {
+				return fmt.Errorf(
+					"event %d (%v): InitTransition before previous transition finished",
+					i, e)
+			}
+			active = e.Duration >= 0
+			duration = e.Duration
+		case TransitionStep:
+			if !active || duration == 0 {
+				return fmt.Errorf("event %d (%v): TransitionStep outside of transition",
+					i, e)
+			}
+			if e.Elapsed >= duration {
+				return fmt.Errorf("event %d (%v): elapsed time exceeds duration %v",
+					i, e, duration)
+			}
+			needsRender = true
+		case FinishTransition:
+			if !active {
+				return fmt.Errorf(
+					"event %d (%v): FinishTransition without matching InitTransition",
+					i, e)
+			}
+			active = false
+			needsRender = true
+		case InterruptTransition:
+			if !active {
+				return fmt.Errorf(
+					"event %d (%v): InterruptTransition outside of transition", i, e)
+			}
+			if e.Interruption == modules.Merge {
+				duration = e.Duration
+			}
+		case IdleStep:
+			if active {
+				return fmt.Errorf("event %d (%v): IdleStep during transition", i, e)
+			}
+			needsRender = true
+		}
+	}
+	if needsRender {
+		return fmt.Errorf("missing Render after %v", events[len(events)-1])
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package harness
+
+import (
+	"github.com/QuestScreen/api/groups"
+	"github.com/QuestScreen/api/resources"
+	"github.com/QuestScreen/api/server"
+)
+
+// Context is a server.Context whose data is set up by the test.
+type Context struct {
+	// Resources contains the resources of each collection index.
+	Resources map[resources.CollectionIndex][]resources.Resource
+	// Textures is the list of available textures.
+	Textures []resources.Resource
+	// FontFamilies contains the names of the available font families.
+	FontFamilies []string
+	// Group is the active group. If nil, a group without heroes is used.
+	Group groups.Group
+}
+
+// GetResources returns the resources of the given collection.
+func (c *Context) GetResources(index resources.CollectionIndex) []resources.Resource {
+	return c.Resources[index]
+}
+
+// GetTextures returns the list of textures.
+func (c *Context) GetTextures() []resources.Resource {
+	return c.Textures
+}
+
+// NumFontFamilies returns the number of font families.
+func (c *Context) NumFontFamilies() int {
+	return len(c.FontFamilies)
+}
+
+// FontFamilyName returns the name of the font family at the given index.
+func (c *Context) FontFamilyName(index int) string {
+	return c.FontFamilies[index]
+}
+
+// ActiveGroup returns the configured group.
+func (c *Context) ActiveGroup() groups.Group {
+	if c.Group == nil {
+		return &Group{}
+	}
+	return c.Group
+}
+
+var _ server.Context = (*Context)(nil)
+
+type hero struct {
+	name, id, description string
+}
+
+func (h *hero) Name() string {
+	return h.name
+}
+
+func (h *hero) ID() string {
+	return h.id
+}
+
+func (h *hero) Description() string {
+	return h.description
+}
+
+// NewHero creates a hero with the given data.
+func NewHero(name, id, description string) groups.Hero {
+	return &hero{name: name, id: id, description: description}
+}
+
+// Group is a groups.Group that is also its own groups.HeroList.
+type Group struct {
+	Members []groups.Hero
+}
+
+// Heroes returns the group itself.
+func (g *Group) Heroes() groups.HeroList {
+	return g
+}
+
+// Hero returns the hero at the given index.
+func (g *Group) Hero(index int) groups.Hero {
+	return g.Members[index]
+}
+
+// NumHeroes returns the number of heroes.
+func (g *Group) NumHeroes() int {
+	return len(g.Members)
+}
+
+// Messages is a server.MessageSender that collects all messages.
+type Messages struct {
+	Warnings, Errors []string
+}
+
+// Warning appends the given text to Warnings.
+func (m *Messages) Warning(text string) {
+	m.Warnings = append(m.Warnings, text)
+}
+
+// Error appends the given text to Errors.
+func (m *Messages) Error(text string) {
+	m.Errors = append(m.Errors, text)
+}
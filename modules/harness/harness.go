@@ -0,0 +1,328 @@
+package harness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/QuestScreen/api/groups"
+	"github.com/QuestScreen/api/modules"
+	"github.com/QuestScreen/api/render"
+	"github.com/QuestScreen/api/server"
+	"gopkg.in/yaml.v3"
+)
+
+// Clock is a virtual clock controlled by the test.
+type Clock struct {
+	now time.Duration
+}
+
+// Now returns the time that has passed since the clock has been created.
+func (c *Clock) Now() time.Duration {
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.now += d
+}
+
+// Harness runs a module the way the main app does, with fake server-side
+// data and a virtual clock.
+//
+// It drives the module's State and Renderer through the documented call
+// sequences:
+//
+//	Rebuild -> Render
+//	Post -> InitTransition -> (TransitionStep -> Render)* ->
+//	    FinishTransition -> Render
+//	IdleStep -> Render
+//
+// Data posted during a transition is handled like the main app does,
+// according to modules.TransitionInterrupter if the Renderer implements it.
+//
+// Time only passes when Advance is called; transition steps are issued
+// according to the virtual clock. If the Renderer implements
+// modules.IdleAnimator, each Advance outside of a transition renders an idle
+// frame while the Renderer requests it. All calls to the Renderer are recorded as
+// events that can be checked with Verify.
+//
+// A Harness is not safe for concurrent use.
+type Harness struct {
+	// Backend is the render.Renderer handed to the module's Renderer.
+	Backend render.Renderer
+	// Context is the server.Context handed to the module's State.
+	Context *Context
+	// Messages collects the messages issued by the module.
+	Messages Messages
+	// Clock is the virtual clock transitions are measured with.
+	Clock Clock
+	// Config is handed to the Renderer on Rebuild. It is initialized with the
+	// module's DefaultConfig.
+	Config interface{}
+	// State and Renderer are the objects created by the module.
+	State    modules.State
+	Renderer modules.Renderer
+
+	events   []Event
+	active   bool
+	start    time.Duration
+	duration time.Duration
+	// idling is true iff IdleStep has been called since the last transition
+	// and IdleActive has not returned false since.
+	idling   bool
+	lastIdle time.Duration
+	// queue contains data that waits for the current transition to end.
+	queue []interface{}
+}
+
+// New creates the Renderer and State of the given module. input is handed to
+// the module's CreateState and may be nil.
+//
+// The Renderer is not rebuilt automatically; call Rebuild before issuing any
+// other calls, like the main app does.
+func New(module *modules.Module, backend render.Renderer, ctx *Context,
+	input *yaml.Node) (*Harness, error) {
+	h := &Harness{Backend: backend, Context: ctx, Config: module.DefaultConfig}
+	var err error
+	if h.Renderer, err = module.CreateRenderer(backend, &h.Messages); err != nil {
+		return nil, err
+	}
+	if h.State, err = module.CreateState(input, ctx, &h.Messages); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Events returns all events recorded since creation or the last call to
+// ResetEvents.
+func (h *Harness) Events() []Event {
+	return h.events
+}
+
+// ResetEvents discards all recorded events.
+func (h *Harness) ResetEvents() {
+	h.events = nil
+}
+
+// Verify checks the recorded events with the package-level Verify func and
+// reports a violation as test error.
+func (h *Harness) Verify(tb testing.TB) {
+	tb.Helper()
+	if err := Verify(h.events); err != nil {
+		tb.Error(err)
+	}
+}
+
+// InTransition returns true iff a transition is currently in progress.
+func (h *Harness) InTransition() bool {
+	return h.active
+}
+
+func (h *Harness) record(e Event) {
+	e.Time = h.Clock.Now()
+	h.events = append(h.events, e)
+}
+
+func (h *Harness) render() {
+	h.record(Event{Kind: Render})
+	h.Renderer.Render(h.Backend)
+}
+
+func (h *Harness) finish() {
+	h.active = false
+	h.record(Event{Kind: FinishTransition})
+	h.Renderer.FinishTransition(h.Backend)
+	h.render()
+	// data whose InitTransition does not start a transition must not keep
+	// the data queued after it waiting.
+	for !h.active && len(h.queue) > 0 {
+		data := h.queue[0]
+		h.queue = h.queue[1:]
+		h.startTransition(data)
+	}
+}
+
+// Queued returns the number of data objects that wait for the current
+// transition to finish.
+func (h *Harness) Queued() int {
+	return len(h.queue)
+}
+
+// Rebuild rebuilds the Renderer with data created by the State and the
+// current Config, like the main app does after a scene or group change.
+//
+// A transition in progress is finished first; queued data is discarded.
+func (h *Harness) Rebuild() {
+	h.rebuild(h.State.CreateRendererData(h.Context))
+}
+
+// Reconfigure sets the Config and rebuilds the Renderer without data, like
+// the main app does after a pure config change.
+//
+// A transition in progress is finished first; queued data is discarded.
+func (h *Harness) Reconfigure(config interface{}) {
+	h.Config = config
+	h.rebuild(nil)
+}
+
+func (h *Harness) rebuild(data interface{}) {
+	h.queue = nil
+	if h.active {
+		h.finish()
+	}
+	h.record(Event{Kind: Rebuild})
+	h.Renderer.Rebuild(h.Backend, data, h.Config)
+	h.render()
+}
+
+// Post sends the payload to the pure endpoint at the given index and starts
+// a transition with the resulting data unless an error is returned.
+// The first return value is what would have been sent to the client.
+//
+// A transition in progress is interrupted, see modules.TransitionInterrupter.
+func (h *Harness) Post(index int, payload []byte) (interface{}, server.Error) {
+	provider, ok := h.State.(modules.PureEndpointProvider)
+	if !ok {
+		return nil, &server.InternalError{
+			Description: "state does not implement PureEndpointProvider"}
+	}
+	response, data, err := provider.PureEndpoint(index).Post(payload)
+	if err == nil {
+		h.initTransition(data)
+	}
+	return response, err
+}
+
+// PostID works like Post, but for the id endpoint at the given index.
+func (h *Harness) PostID(index int, id string,
+	payload []byte) (interface{}, server.Error) {
+	provider, ok := h.State.(modules.IDEndpointProvider)
+	if !ok {
+		return nil, &server.InternalError{
+			Description: "state does not implement IDEndpointProvider"}
+	}
+	response, data, err := provider.IDEndpoint(index).Post(id, payload)
+	if err == nil {
+		h.initTransition(data)
+	}
+	return response, err
+}
+
+func (h *Harness) initTransition(data interface{}) {
+	if h.active {
+		if len(h.queue) > 0 {
+			h.queue = append(h.queue, data)
+			return
+		}
+		mode := modules.JumpToEnd
+		var d time.Duration
+		if interrupter, ok := h.Renderer.(modules.TransitionInterrupter); ok {
+			elapsed := h.Clock.Now() - h.start
+			mode, d = interrupter.InterruptTransition(h.Backend, elapsed, data)
+			h.record(Event{Kind: InterruptTransition, Elapsed: elapsed,
+				Interruption: mode, Duration: d})
+		}
+		switch mode {
+		case modules.Queue:
+			h.queue = append(h.queue, data)
+			return
+		case modules.Merge:
+			h.duration = d
+			return
+		default:
+			h.finish()
+		}
+	}
+	h.startTransition(data)
+}
+
+func (h *Harness) startTransition(data interface{}) {
+	d := h.Renderer.InitTransition(h.Backend, data)
+	h.record(Event{Kind: InitTransition, Duration: d})
+	if d >= 0 {
+		h.idling = false
+	}
+	switch {
+	case d == 0:
+		h.active = true
+		h.finish()
+	case d > 0:
+		h.active = true
+		h.start = h.Clock.Now()
+		h.duration = d
+	}
+}
+
+// HeroListChanged notifies the State about a change in the list of heroes.
+// The State must implement modules.HeroAwareState.
+func (h *Harness) HeroListChanged(action groups.HeroChangeAction,
+	heroIndex int) {
+	h.State.(modules.HeroAwareState).HeroListChanged(h.Context, action,
+		heroIndex)
+}
+
+// Advance moves the clock forward by d and then renders a frame if a
+// transition is in progress: If the transition's duration has passed,
+// FinishTransition and Render are called, else TransitionStep and Render.
+//
+// Outside of a transition, if the Renderer implements modules.IdleAnimator
+// and its IdleActive returns true, IdleStep and Render are called.
+func (h *Harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+	if !h.active {
+		h.idle()
+		return
+	}
+	elapsed := h.Clock.Now() - h.start
+	if elapsed >= h.duration {
+		h.finish()
+	} else {
+		h.record(Event{Kind: TransitionStep, Elapsed: elapsed})
+		h.Renderer.TransitionStep(h.Backend, elapsed)
+		h.render()
+	}
+}
+
+func (h *Harness) idle() {
+	animator, ok := h.Renderer.(modules.IdleAnimator)
+	if !ok || !animator.IdleActive() {
+		h.idling = false
+		return
+	}
+	var delta time.Duration
+	if h.idling {
+		delta = h.Clock.Now() - h.lastIdle
+	}
+	h.idling = true
+	h.lastIdle = h.Clock.Now()
+	h.record(Event{Kind: IdleStep, Delta: delta})
+	animator.IdleStep(h.Backend, delta)
+	h.render()
+}
+
+// Run calls Advance with the given frame duration until total has passed.
+// Use it to run idle animations; frame must be positive.
+func (h *Harness) Run(total, frame time.Duration) {
+	if frame <= 0 {
+		panic("frame duration must be positive")
+	}
+	for end := h.Clock.Now() + total; h.Clock.Now() < end; {
+		step := frame
+		if rest := end - h.Clock.Now(); rest < step {
+			step = rest
+		}
+		h.Advance(step)
+	}
+}
+
+// RunTransition calls Advance with the given frame duration until the
+// current transition has finished. Does nothing if no transition is in
+// progress. frame must be positive.
+func (h *Harness) RunTransition(frame time.Duration) {
+	if frame <= 0 {
+		panic("frame duration must be positive")
+	}
+	for h.active {
+		h.Advance(frame)
+	}
+}
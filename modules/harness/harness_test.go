@@ -0,0 +1,237 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QuestScreen/api/modules"
+	"github.com/QuestScreen/api/render"
+	"github.com/QuestScreen/api/server"
+	"gopkg.in/yaml.v3"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		err    string
+	}{
+		{"valid", []Event{{Kind: Rebuild}, {Kind: Render},
+			{Kind: InitTransition, Duration: time.Second},
+			{Kind: TransitionStep, Elapsed: time.Millisecond}, {Kind: Render},
+			{Kind: InterruptTransition, Interruption: modules.Merge,
+				Duration: 2 * time.Second},
+			{Kind: TransitionStep, Elapsed: time.Second}, {Kind: Render},
+			{Kind: FinishTransition}, {Kind: Render},
+			{Kind: InitTransition, Duration: -1},
+			{Kind: IdleStep}, {Kind: Render}}, ""},
+		{"unfinished transition",
+			[]Event{{Kind: InitTransition, Duration: time.Second}}, ""},
+		{"missing render", []Event{{Kind: Rebuild}, {Kind: IdleStep}},
+			"expected Render after Rebuild"},
+		{"missing final render", []Event{{Kind: Rebuild}}, "missing Render"},
+		{"step without transition", []Event{
+			{Kind: TransitionStep, Elapsed: time.Millisecond}, {Kind: Render}},
+			"TransitionStep outside of transition"},
+		{"step in zero transition", []Event{{Kind: InitTransition},
+			{Kind: TransitionStep}, {Kind: Render}},
+			"TransitionStep outside of transition"},
+		{"step after duration", []Event{
+			{Kind: InitTransition, Duration: time.Second},
+			{Kind: TransitionStep, Elapsed: time.Second}, {Kind: Render}},
+			"elapsed time exceeds duration"},
+		{"finish without init", []Event{{Kind: FinishTransition},
+			{Kind: Render}}, "FinishTransition without matching InitTransition"},
+		{"finish after negative duration", []Event{
+			{Kind: InitTransition, Duration: -1}, {Kind: FinishTransition},
+			{Kind: Render}}, "FinishTransition without matching InitTransition"},
+		{"nested init", []Event{{Kind: InitTransition, Duration: time.Second},
+			{Kind: InitTransition, Duration: time.Second}},
+			"InitTransition before previous transition finished"},
+		{"rebuild during transition", []Event{
+			{Kind: InitTransition, Duration: time.Second}, {Kind: Rebuild},
+			{Kind: Render}}, "Rebuild during transition"},
+		{"idle during transition", []Event{
+			{Kind: InitTransition, Duration: time.Second}, {Kind: IdleStep},
+			{Kind: Render}}, "IdleStep during transition"},
+		{"interrupt without transition", []Event{
+			{Kind: InterruptTransition, Interruption: modules.Queue}},
+			"InterruptTransition outside of transition"},
+	}
+	for _, tt := range tests {
+		err := Verify(tt.events)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && err == nil:
+			t.Errorf("%s: expected error containing %q", tt.name, tt.err)
+		case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+			t.Errorf("%s: error %q does not contain %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+// testRenderer uses the posted durations as transition durations and
+// answers each interruption with mode. For Merge, it returns merged.
+type testRenderer struct {
+	mode   modules.Interruption
+	merged time.Duration
+}
+
+func (r *testRenderer) Rebuild(ctx render.Renderer, data interface{},
+	config interface{}) {
+}
+
+func (r *testRenderer) InitTransition(ctx render.Renderer,
+	data interface{}) time.Duration {
+	return data.(time.Duration)
+}
+
+func (r *testRenderer) TransitionStep(ctx render.Renderer,
+	elapsed time.Duration) {
+}
+
+func (r *testRenderer) FinishTransition(ctx render.Renderer) {}
+
+func (r *testRenderer) Render(ctx render.Renderer) {}
+
+func (r *testRenderer) InterruptTransition(ctx render.Renderer,
+	elapsed time.Duration, data interface{}) (modules.Interruption,
+	time.Duration) {
+	return r.mode, r.merged
+}
+
+// testState is its own only pure endpoint. Posting a duration string
+// creates a transition of that duration.
+type testState struct{}
+
+func (s testState) Send(ctx server.Context) interface{} {
+	return nil
+}
+
+func (s testState) Persist(ctx server.Context) interface{} {
+	return nil
+}
+
+func (s testState) CreateRendererData(ctx server.Context) interface{} {
+	return nil
+}
+
+func (s testState) PureEndpoint(index int) modules.PureEndpoint {
+	return s
+}
+
+func (s testState) Post(payload []byte) (interface{}, interface{},
+	server.Error) {
+	d, err := time.ParseDuration(string(payload))
+	if err != nil {
+		return nil, nil, &server.BadRequest{Inner: err, Message: "invalid duration"}
+	}
+	return nil, d, nil
+}
+
+func newTestHarness(t *testing.T, r *testRenderer) *Harness {
+	module := &modules.Module{Name: "Test", ID: "test",
+		EndpointPaths: []string{""},
+		CreateRenderer: func(backend render.Renderer,
+			ms server.MessageSender) (modules.Renderer, error) {
+			return r, nil
+		},
+		CreateState: func(input *yaml.Node, ctx server.Context,
+			ms server.MessageSender) (modules.State, error) {
+			return testState{}, nil
+		}}
+	h, err := New(module, nil, &Context{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Rebuild()
+	h.ResetEvents()
+	return h
+}
+
+func TestInterruption(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer testRenderer
+		expected []string
+	}{
+		{"JumpToEnd", testRenderer{mode: modules.JumpToEnd}, []string{
+			"0s InitTransition -> 100ms",
+			"40ms TransitionStep(40ms)", "40ms Render",
+			"40ms InterruptTransition(40ms) -> JumpToEnd",
+			"40ms FinishTransition", "40ms Render",
+			"40ms InitTransition -> 50ms",
+			// the second post arrives while nothing is queued.
+			"40ms InterruptTransition(0s) -> JumpToEnd",
+			"40ms FinishTransition", "40ms Render",
+			"40ms InitTransition -> 30ms",
+			"80ms FinishTransition", "80ms Render"}},
+		{"Queue", testRenderer{mode: modules.Queue}, []string{
+			"0s InitTransition -> 100ms",
+			"40ms TransitionStep(40ms)", "40ms Render",
+			// the second post is queued without asking the renderer.
+			"40ms InterruptTransition(40ms) -> Queue",
+			"80ms TransitionStep(80ms)", "80ms Render",
+			"120ms FinishTransition", "120ms Render",
+			"120ms InitTransition -> 50ms",
+			"160ms TransitionStep(40ms)", "160ms Render",
+			"200ms FinishTransition", "200ms Render",
+			"200ms InitTransition -> 30ms",
+			"240ms FinishTransition", "240ms Render"}},
+		{"Merge", testRenderer{mode: modules.Merge, merged: 150 * time.Millisecond},
+			[]string{
+				"0s InitTransition -> 100ms",
+				"40ms TransitionStep(40ms)", "40ms Render",
+				"40ms InterruptTransition(40ms) -> Merge 150ms",
+				"40ms InterruptTransition(40ms) -> Merge 150ms",
+				"80ms TransitionStep(80ms)", "80ms Render",
+				"120ms TransitionStep(120ms)", "120ms Render",
+				"160ms FinishTransition", "160ms Render"}},
+	}
+	for _, tt := range tests {
+		h := newTestHarness(t, &tt.renderer)
+		if _, err := h.Post(0, []byte("100ms")); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		h.Advance(40 * time.Millisecond)
+		for _, payload := range []string{"50ms", "30ms"} {
+			if _, err := h.Post(0, []byte(payload)); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		h.RunTransition(40 * time.Millisecond)
+		h.Verify(t)
+
+		events := h.Events()
+		var actual []string
+		for _, e := range events {
+			actual = append(actual, e.String())
+		}
+		if a, e := strings.Join(actual, "\n"),
+			strings.Join(tt.expected, "\n"); a != e {
+			t.Errorf("%s: got events\n%s\nwant\n%s", tt.name, a, e)
+		}
+	}
+}
+
+func TestRebuildDiscardsQueue(t *testing.T) {
+	h := newTestHarness(t, &testRenderer{mode: modules.Queue})
+	h.Post(0, []byte("100ms"))
+	h.Post(0, []byte("50ms"))
+	if h.Queued() != 1 {
+		t.Fatalf("queued = %d, want 1", h.Queued())
+	}
+	h.Rebuild()
+	if h.Queued() != 0 || h.InTransition() {
+		t.Errorf("after Rebuild: queued = %d, in transition = %v", h.Queued(),
+			h.InTransition())
+	}
+	for _, e := range h.Events() {
+		if e.Kind == InitTransition && e.Duration == 50*time.Millisecond {
+			t.Error("queued data was given to InitTransition")
+		}
+	}
+	h.Verify(t)
+}
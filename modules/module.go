@@ -188,6 +188,11 @@ type Renderer interface {
 	// if 0 is returned, TransitionStep will never be called; if a negative
 	// value is returned, neither FinishTransition nor Render will be
 	// called.
+	//
+	// If new data arrives while a transition is in progress, the transition is
+	// interrupted. By default, FinishTransition and Render are called for the
+	// current transition immediately, followed by InitTransition with the new
+	// data. Implement TransitionInterrupter to change this.
 	InitTransition(ctx render.Renderer, data interface{}) time.Duration
 	// TransitionStep should update the renderer's current state while
 	// transitioning. A call to TransitionStep() will always immediately be
@@ -206,3 +211,79 @@ type Renderer interface {
 	// Render renders the Module's current state.
 	Render(ctx render.Renderer)
 }
+
+// IdleAnimator is a Renderer extension for modules that display continuous
+// animations which are not bound to a transition, like drifting fog, a
+// pulsing marker or a ticking clock.
+//
+// In each frame in which the module is not transitioning, the render thread
+// calls IdleActive. If it returns true, IdleStep is called, immediately
+// followed by Render. Thus, the module requests per-frame updates by
+// returning true from IdleActive and stops them by returning false.
+//
+// This does not change the contract of Renderer: During a transition,
+// neither IdleActive nor IdleStep will be called; idle updates resume in the
+// frame after FinishTransition.
+type IdleAnimator interface {
+	// IdleActive returns true iff the renderer currently wants per-frame
+	// updates. This should be a cheap getter as it will be called for every
+	// frame.
+	IdleActive() bool
+	// IdleStep should update the renderer's current state for the next frame.
+	// A call to IdleStep will always immediately be followed by a call to
+	// Render.
+	//
+	// delta is the time that has passed since the previous call to IdleStep.
+	// It is 0 for the first call after IdleActive started returning true and
+	// for the first call after a transition.
+	IdleStep(ctx render.Renderer, delta time.Duration)
+}
+
+// Interruption defines how a transition in progress is handled when new data
+// for the renderer arrives.
+type Interruption int
+
+const (
+	// JumpToEnd finishes the current transition immediately by calling
+	// FinishTransition and Render, and then calls InitTransition with the new
+	// data. This is the behavior for renderers that do not implement
+	// TransitionInterrupter.
+	JumpToEnd Interruption = iota
+	// Queue lets the current transition run until its end. The new data is
+	// given to InitTransition right after FinishTransition and Render have
+	// been called for the current transition.
+	Queue
+	// Merge continues the current transition, which now also incorporates the
+	// new data. InitTransition will not be called for the new data, and
+	// FinishTransition will be called only once for the merged transition.
+	Merge
+)
+
+// TransitionInterrupter is a Renderer extension for modules that want to
+// control what happens when a transition is superseded by new data, e.g.
+// because the user clicked twice in quick succession.
+//
+// The following guarantees hold regardless of the chosen Interruption:
+//
+//   - Data is processed in the order in which it arrived. While data is
+//     queued, InterruptTransition is not called; any new data is appended to
+//     the queue.
+//   - FinishTransition is called exactly once for each call to
+//     InitTransition that returned a non-negative value.
+//   - If the renderer is rebuilt while data is queued, the current transition
+//     is finished and the queued data is discarded without calling
+//     InitTransition, since Rebuild receives the complete current state.
+type TransitionInterrupter interface {
+	// InterruptTransition is called when new data arrives while a transition
+	// is in progress and no data is queued. elapsed is the time that has
+	// passed since the current transition started, data is the new data.
+	//
+	// The returned Interruption defines how to proceed. For Merge, the
+	// renderer must have incorporated data into the current transition and
+	// returns the new total duration of the current transition, measured from
+	// its start. If that duration is not greater than elapsed, the transition
+	// finishes with the next frame. For the other choices, the returned
+	// duration is ignored.
+	InterruptTransition(ctx render.Renderer, elapsed time.Duration,
+		data interface{}) (Interruption, time.Duration)
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+// Frame is a single frame of an AnimatedImage.
+type Frame struct {
+	// Image contains the frame. Multiple frames may share the same Image, e.g.
+	// for sprite strips.
+	Image Image
+	// Region is the part of Image that contains the frame, see
+	// Image.DrawRegion.
+	Region Rectangle
+	// Delay is the time the frame is shown.
+	Delay time.Duration
+}
+
+// AnimatedImage is a sequence of frames that are shown one after another.
+// It can be loaded from GIF and APNG files via the Renderer, or created from
+// a sprite strip with NewSpriteStrip.
+//
+// An AnimatedImage does not track time itself; its Draw function takes the
+// time elapsed since the animation started. This allows it to be used both
+// from TransitionStep and from an idle animation loop.
+type AnimatedImage struct {
+	Frames []Frame
+	// Plays is the number of times the animation is played. After the last
+	// play, the last frame is shown indefinitely. 0 means the animation is
+	// repeated forever.
+	Plays int
+}
+
+// NewSpriteStrip creates an animated image from a horizontal sprite strip,
+// i.e. an image that contains the given number of equally wide frames side by
+// side. Each frame is shown for the given delay. The animation is repeated
+// forever.
+func NewSpriteStrip(image Image, frames int,
+	delay time.Duration) (AnimatedImage, error) {
+	if frames <= 0 {
+		return AnimatedImage{}, errors.New("sprite strip needs at least one frame")
+	}
+	sheet, err := NewSpriteSheet(image, frames, 1, 0)
+	if err != nil {
+		return AnimatedImage{}, err
+	}
+	ret := AnimatedImage{Frames: make([]Frame, frames)}
+	for i := range ret.Frames {
+		ret.Frames[i] = Frame{Image: image, Region: sheet.Region(i), Delay: delay}
+	}
+	return ret, nil
+}
+
+// LoadSpriteStrip loads the image file at the given URL, e.g. the Location of
+// a resources.Resource, and creates a sprite strip from it, see
+// NewSpriteStrip.
+func LoadSpriteStrip(r Renderer, path *url.URL, frames int,
+	delay time.Duration, scaleDownToOutput bool) (AnimatedImage, error) {
+	image, err := r.LoadImageFile(path, scaleDownToOutput)
+	if err != nil {
+		return AnimatedImage{}, err
+	}
+	ret, err := NewSpriteStrip(image, frames, delay)
+	if err != nil {
+		r.FreeImage(&image)
+	}
+	return ret, err
+}
+
+// IsEmpty tests whether the animated image has no frames.
+func (a *AnimatedImage) IsEmpty() bool {
+	return len(a.Frames) == 0
+}
+
+// Len returns the number of frames.
+func (a *AnimatedImage) Len() int {
+	return len(a.Frames)
+}
+
+// Width returns the width of the first frame in pixels.
+func (a *AnimatedImage) Width() int32 {
+	if a.IsEmpty() {
+		return 0
+	}
+	return a.Frames[0].Region.Width
+}
+
+// Height returns the height of the first frame in pixels.
+func (a *AnimatedImage) Height() int32 {
+	if a.IsEmpty() {
+		return 0
+	}
+	return a.Frames[0].Region.Height
+}
+
+// Duration returns the time it takes to play the animation once.
+func (a *AnimatedImage) Duration() time.Duration {
+	var ret time.Duration
+	for i := range a.Frames {
+		ret += a.Frames[i].Delay
+	}
+	return ret
+}
+
+// Finished returns true iff the animation has been played the requested
+// number of times after the given time. Always false if Plays is 0.
+func (a *AnimatedImage) Finished(elapsed time.Duration) bool {
+	return a.Plays > 0 && elapsed >= a.Duration()*time.Duration(a.Plays)
+}
+
+// FrameAt returns the index of the frame shown at the given time since the
+// start of the animation. Returns -1 if the image has no frames.
+func (a *AnimatedImage) FrameAt(elapsed time.Duration) int {
+	if a.IsEmpty() {
+		return -1
+	}
+	total := a.Duration()
+	if total <= 0 || elapsed < 0 {
+		return 0
+	}
+	if a.Finished(elapsed) {
+		return len(a.Frames) - 1
+	}
+	elapsed %= total
+	for i := range a.Frames {
+		if elapsed < a.Frames[i].Delay {
+			return i
+		}
+		elapsed -= a.Frames[i].Delay
+	}
+	return len(a.Frames) - 1
+}
+
+// Draw draws the frame shown at the given time since the start of the
+// animation to the given rectangular area. The frame will be stretched to
+// fit the whole area.
+func (a *AnimatedImage) Draw(r Renderer, elapsed time.Duration,
+	area Rectangle, alpha uint8) {
+	index := a.FrameAt(elapsed)
+	if index == -1 {
+		return
+	}
+	f := &a.Frames[index]
+	f.Image.DrawRegion(r, f.Region, area, alpha)
+}
+
+// Free destroys the textures of all frames and removes all frames.
+func (a *AnimatedImage) Free(r Renderer) {
+	for i := range a.Frames {
+		image := a.Frames[i].Image
+		if image.IsEmpty() {
+			continue
+		}
+		r.FreeImage(&image)
+		// frames may share their image
+		for j := i + 1; j < len(a.Frames); j++ {
+			if a.Frames[j].Image.TextureID == a.Frames[i].Image.TextureID {
+				a.Frames[j].Image = EmptyImage()
+			}
+		}
+	}
+	a.Frames = nil
+}
@@ -0,0 +1,221 @@
+package animation
+
+import (
+	"math"
+	"time"
+)
+
+// Curve maps the progress of a segment, which is a value between 0.0 and 1.0,
+// to the progress of the animated value. A Curve should return 0.0 for 0.0 and
+// 1.0 for 1.0; values outside of that range between the two are allowed and
+// cause the value to overshoot.
+type Curve func(progress float32) float32
+
+// Linear is a Curve that does not modify the progress.
+func Linear(progress float32) float32 {
+	return progress
+}
+
+// Cubic is a Curve that accelerates in the beginning and decelerates towards
+// the end. It is equivalent to render.TransitionCurve.Cubic.
+func Cubic(progress float32) float32 {
+	x := float64(progress)
+	return float32(-2.0*math.Pow(x, 3) + 3.0*math.Pow(x, 2))
+}
+
+// Animation is a value or a group of values that change over a fixed
+// duration.
+//
+// Animations are designed to be used as transitions of a module renderer:
+// Return the Animation's Duration from InitTransition, call Apply with the
+// elapsed time in TransitionStep and call Finish in FinishTransition. An
+// animation is typically created in InitTransition from the renderer's
+// current state and the data it received.
+type Animation interface {
+	// Duration returns the total length of the animation.
+	Duration() time.Duration
+	// Apply sets the animated values to the state at the given elapsed time.
+	// elapsed is clamped to the range between 0 and Duration().
+	Apply(elapsed time.Duration)
+}
+
+// Finish sets the animated values of the given animation to their final
+// state.
+func Finish(a Animation) {
+	a.Apply(a.Duration())
+}
+
+func clamp(elapsed, duration time.Duration) time.Duration {
+	if elapsed < 0 {
+		return 0
+	} else if elapsed > duration {
+		return duration
+	}
+	return elapsed
+}
+
+// overshooter is implemented by animations that can extrapolate their values
+// beyond their duration. Curves that overshoot rely on this.
+type overshooter interface {
+	// overshoot works like Apply, but does not clamp elapsed. Values before 0
+	// and after Duration() are extrapolated.
+	overshoot(elapsed time.Duration)
+}
+
+// overshoot applies a at the given elapsed time without clamping it, if a
+// supports this. Otherwise, elapsed is clamped by a's Apply.
+func overshoot(a Animation, elapsed time.Duration) {
+	if o, ok := a.(overshooter); ok {
+		o.overshoot(elapsed)
+	} else {
+		a.Apply(elapsed)
+	}
+}
+
+type hold time.Duration
+
+func (h hold) Duration() time.Duration {
+	return time.Duration(h)
+}
+
+func (h hold) Apply(elapsed time.Duration) {}
+
+// Hold returns an animation that does nothing for the given duration.
+// Use it in a Sequence to wait between two animations.
+func Hold(duration time.Duration) Animation {
+	return hold(duration)
+}
+
+type sequence struct {
+	items    []Animation
+	duration time.Duration
+}
+
+// Sequence returns an animation that runs the given animations one after
+// another.
+//
+// Apply sets all animations before the current one to their final state, in
+// order, before applying the current one. Animations after the current one
+// are not applied, so later animations may animate the same values as
+// earlier ones, starting where those have ended.
+func Sequence(items ...Animation) Animation {
+	ret := &sequence{items: items}
+	for _, item := range items {
+		ret.duration += item.Duration()
+	}
+	return ret
+}
+
+func (s *sequence) Duration() time.Duration {
+	return s.duration
+}
+
+func (s *sequence) overshoot(elapsed time.Duration) {
+	n := len(s.items)
+	switch {
+	case n == 0 || (elapsed >= 0 && elapsed <= s.duration):
+		s.Apply(elapsed)
+	case elapsed < 0:
+		overshoot(s.items[0], elapsed)
+	default:
+		for _, item := range s.items[:n-1] {
+			item.Apply(item.Duration())
+		}
+		last := s.items[n-1]
+		overshoot(last, elapsed-s.duration+last.Duration())
+	}
+}
+
+func (s *sequence) Apply(elapsed time.Duration) {
+	elapsed = clamp(elapsed, s.duration)
+	for _, item := range s.items {
+		d := item.Duration()
+		if elapsed < d {
+			item.Apply(elapsed)
+			return
+		}
+		item.Apply(d)
+		elapsed -= d
+	}
+}
+
+type parallel struct {
+	items    []Animation
+	duration time.Duration
+}
+
+// Parallel returns an animation that runs the given animations at the same
+// time. Its duration is the longest duration of the given animations;
+// shorter animations keep their final state until it is over.
+//
+// If multiple animations animate the same value, the latter one wins.
+func Parallel(items ...Animation) Animation {
+	ret := &parallel{items: items}
+	for _, item := range items {
+		if d := item.Duration(); d > ret.duration {
+			ret.duration = d
+		}
+	}
+	return ret
+}
+
+func (p *parallel) Duration() time.Duration {
+	return p.duration
+}
+
+func (p *parallel) overshoot(elapsed time.Duration) {
+	if elapsed >= 0 && elapsed <= p.duration {
+		p.Apply(elapsed)
+		return
+	}
+	for _, item := range p.items {
+		// animations that end early keep their final state.
+		if d := item.Duration(); elapsed < 0 || d == p.duration {
+			overshoot(item, elapsed)
+		} else {
+			item.Apply(d)
+		}
+	}
+}
+
+func (p *parallel) Apply(elapsed time.Duration) {
+	elapsed = clamp(elapsed, p.duration)
+	for _, item := range p.items {
+		item.Apply(clamp(elapsed, item.Duration()))
+	}
+}
+
+type curved struct {
+	Animation
+	curve Curve
+}
+
+// WithCurve returns an animation that runs the given animation along the
+// given curve. For example, a Sequence wrapped with Cubic starts slowly and
+// slows down at the end as a whole.
+//
+// If the curve overshoots, tracks as well as Sequence and Parallel
+// animations of tracks extrapolate their first or last segment linearly.
+// Other animations, including nested curved animations, are applied clamped
+// to their duration.
+func WithCurve(a Animation, curve Curve) Animation {
+	return curved{Animation: a, curve: curve}
+}
+
+func (c curved) Apply(elapsed time.Duration) {
+	d := c.Duration()
+	if d <= 0 {
+		c.Animation.Apply(elapsed)
+		return
+	}
+	elapsed = clamp(elapsed, d)
+	progress := float64(c.curve(float32(float64(elapsed) / float64(d))))
+	switch {
+	case progress == 0:
+		c.Animation.Apply(0)
+	case progress == 1:
+		c.Animation.Apply(d)
+	default:
+		overshoot(c.Animation, time.Duration(math.Round(progress*float64(d))))
+	}
+}
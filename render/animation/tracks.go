@@ -0,0 +1,231 @@
+package animation
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+// keyframes implements the timing of a track. set is called with the indexes
+// of the keys surrounding the current time and the curved progress between
+// them.
+type keyframes struct {
+	times  []time.Duration
+	curves []Curve
+	set    func(from, to int, progress float32)
+}
+
+func newKeyframes(times []time.Duration, curves []Curve,
+	set func(from, to int, progress float32)) *keyframes {
+	for i := 1; i < len(times); i++ {
+		if times[i] < times[i-1] {
+			panic("keyframes must be given in chronological order")
+		}
+	}
+	return &keyframes{times: times, curves: curves, set: set}
+}
+
+func (k *keyframes) Duration() time.Duration {
+	if len(k.times) == 0 {
+		return 0
+	}
+	return k.times[len(k.times)-1]
+}
+
+func (k *keyframes) Apply(elapsed time.Duration) {
+	if len(k.times) == 0 {
+		return
+	}
+	elapsed = clamp(elapsed, k.Duration())
+	next := sort.Search(len(k.times), func(i int) bool {
+		return k.times[i] > elapsed
+	})
+	switch next {
+	case 0:
+		k.set(0, 0, 0)
+	case len(k.times):
+		k.set(next-1, next-1, 0)
+	default:
+		curve := k.curves[next]
+		if curve == nil {
+			curve = Linear
+		}
+		k.set(next-1, next, curve(float32(float64(elapsed-k.times[next-1])/
+			float64(k.times[next]-k.times[next-1]))))
+	}
+}
+
+// overshoot extrapolates the first segment linearly before the start and the
+// last segment after the end of the track.
+func (k *keyframes) overshoot(elapsed time.Duration) {
+	n := len(k.times)
+	var from, to int
+	switch {
+	case n < 2 || (elapsed >= 0 && elapsed <= k.Duration()):
+		k.Apply(elapsed)
+		return
+	case elapsed < 0:
+		from, to = 0, 1
+	default:
+		from, to = n-2, n-1
+	}
+	span := k.times[to] - k.times[from]
+	if span <= 0 {
+		k.Apply(elapsed)
+		return
+	}
+	k.set(from, to, float32(float64(elapsed-k.times[from])/float64(span)))
+}
+
+func lerp(from, to, progress float32) float32 {
+	return from + (to-from)*progress
+}
+
+func lerpByte(from, to uint8, progress float32) uint8 {
+	v := math.Round(float64(lerp(float32(from), float32(to), progress)))
+	if v < 0 {
+		return 0
+	} else if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+func lerpInt(from, to int32, progress float32) int32 {
+	return int32(math.Round(float64(lerp(float32(from), float32(to), progress))))
+}
+
+// FloatKey is a keyframe of a Float track.
+type FloatKey struct {
+	// At is the time of the keyframe relative to the start of the track.
+	At    time.Duration
+	Value float32
+	// Curve is used for the segment from the previous keyframe to this one.
+	// nil is equivalent to Linear.
+	Curve Curve
+}
+
+// Float returns an animation that sets target according to the given
+// keyframes, which must be ordered by time. The animation's duration is the
+// time of the last keyframe.
+//
+// Before the first keyframe, target is set to its value.
+func Float(target *float32, keys ...FloatKey) Animation {
+	times := make([]time.Duration, len(keys))
+	curves := make([]Curve, len(keys))
+	for i, key := range keys {
+		times[i], curves[i] = key.At, key.Curve
+	}
+	return newKeyframes(times, curves, func(from, to int, progress float32) {
+		*target = lerp(keys[from].Value, keys[to].Value, progress)
+	})
+}
+
+// ColorKey is a keyframe of a Color track.
+type ColorKey struct {
+	// At is the time of the keyframe relative to the start of the track.
+	At    time.Duration
+	Value api.RGBA
+	// Curve is used for the segment from the previous keyframe to this one.
+	// nil is equivalent to Linear.
+	Curve Curve
+}
+
+// Color returns an animation that sets target according to the given
+// keyframes, see Float. Each channel, including alpha, is interpolated
+// separately.
+func Color(target *api.RGBA, keys ...ColorKey) Animation {
+	times := make([]time.Duration, len(keys))
+	curves := make([]Curve, len(keys))
+	for i, key := range keys {
+		times[i], curves[i] = key.At, key.Curve
+	}
+	return newKeyframes(times, curves, func(from, to int, progress float32) {
+		a, b := keys[from].Value, keys[to].Value
+		*target = api.RGBA{R: lerpByte(a.R, b.R, progress),
+			G: lerpByte(a.G, b.G, progress), B: lerpByte(a.B, b.B, progress),
+			A: lerpByte(a.A, b.A, progress)}
+	})
+}
+
+// RectangleKey is a keyframe of a Rectangle track.
+type RectangleKey struct {
+	// At is the time of the keyframe relative to the start of the track.
+	At    time.Duration
+	Value render.Rectangle
+	// Curve is used for the segment from the previous keyframe to this one.
+	// nil is equivalent to Linear.
+	Curve Curve
+}
+
+// Rectangle returns an animation that sets target according to the given
+// keyframes, see Float. Position and size are interpolated separately and
+// rounded to whole pixels.
+func Rectangle(target *render.Rectangle, keys ...RectangleKey) Animation {
+	times := make([]time.Duration, len(keys))
+	curves := make([]Curve, len(keys))
+	for i, key := range keys {
+		times[i], curves[i] = key.At, key.Curve
+	}
+	return newKeyframes(times, curves, func(from, to int, progress float32) {
+		a, b := keys[from].Value, keys[to].Value
+		*target = render.Rectangle{X: lerpInt(a.X, b.X, progress),
+			Y: lerpInt(a.Y, b.Y, progress), Width: lerpInt(a.Width, b.Width, progress),
+			Height: lerpInt(a.Height, b.Height, progress)}
+	})
+}
+
+// FRectangleKey is a keyframe of an FRectangle track.
+type FRectangleKey struct {
+	// At is the time of the keyframe relative to the start of the track.
+	At    time.Duration
+	Value render.FRectangle
+	// Curve is used for the segment from the previous keyframe to this one.
+	// nil is equivalent to Linear.
+	Curve Curve
+}
+
+// FRectangle returns an animation that sets target according to the given
+// keyframes, see Float. Unlike Rectangle, it does not round to whole pixels,
+// which makes slow movement smoother.
+func FRectangle(target *render.FRectangle, keys ...FRectangleKey) Animation {
+	times := make([]time.Duration, len(keys))
+	curves := make([]Curve, len(keys))
+	for i, key := range keys {
+		times[i], curves[i] = key.At, key.Curve
+	}
+	return newKeyframes(times, curves, func(from, to int, progress float32) {
+		*target = keys[from].Value.Interpolate(keys[to].Value, progress)
+	})
+}
+
+// TransformKey is a keyframe of a Transform track.
+type TransformKey struct {
+	// At is the time of the keyframe relative to the start of the track.
+	At    time.Duration
+	Value render.Transform
+	// Curve is used for the segment from the previous keyframe to this one.
+	// nil is equivalent to Linear.
+	Curve Curve
+}
+
+// Transform returns an animation that sets target according to the given
+// keyframes, see Float. The matrices are interpolated component-wise, which
+// works well for translation and scaling. For rotations, animate the angle
+// with Float instead and build the Transform from it.
+func Transform(target *render.Transform, keys ...TransformKey) Animation {
+	times := make([]time.Duration, len(keys))
+	curves := make([]Curve, len(keys))
+	for i, key := range keys {
+		times[i], curves[i] = key.At, key.Curve
+	}
+	return newKeyframes(times, curves, func(from, to int, progress float32) {
+		a, b := keys[from].Value, keys[to].Value
+		for i := range target {
+			target[i] = lerp(a[i], b[i], progress)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"context"
+	"image"
+	"net/url"
+)
+
+// DecodedImage is an image that has been decoded and scaled, but not yet been
+// uploaded to a texture, see Renderer.DecodeImageFile.
+type DecodedImage struct {
+	// Pixels contains the image data with premultiplied alpha, top row first.
+	Pixels *image.RGBA
+	// HasAlpha is true iff the image has an alpha channel.
+	HasAlpha bool
+}
+
+// ImageOptions defines how an image is scaled when it is loaded.
+type ImageOptions struct {
+	// ScaleDownToOutput scales the image down so that it completely fits into
+	// the output, preserving aspect ratio.
+	ScaleDownToOutput bool
+	// Width and Height define the size the image is scaled to according to
+	// Fit. If one of them is 0, it is calculated from the other one and the
+	// image's aspect ratio. If both are 0, the image keeps its size.
+	//
+	// The image is scaled up if it is smaller.
+	Width, Height int32
+	// Fit defines how the image is scaled into Width and Height. With Cover,
+	// the parts of the image outside of the given size are cut off, so the
+	// resulting image has exactly the given size.
+	Fit FitMode
+}
+
+type decodeResult struct {
+	decoded DecodedImage
+	err     error
+}
+
+// AsyncImage is a handle to an image that is loaded in the background, see
+// LoadImageAsync.
+//
+// The image is decoded and scaled on a worker goroutine. Its texture is
+// created on the render thread during the first call to Poll after decoding
+// has finished, so a module renderer typically polls in TransitionStep or
+// Render until the image is ready.
+type AsyncImage struct {
+	renderer Renderer
+	ctx      context.Context
+	cancel   context.CancelFunc
+	result   chan decodeResult
+	done     bool
+	image    Image
+	err      error
+}
+
+// LoadImageAsync starts loading the image file at the given URL, e.g. the
+// Location of a resources.Resource, in the background. The image is scaled
+// according to the given options.
+//
+// Loading is cancelled when ctx is cancelled or Cancel is called. Using a
+// context that is cancelled when the scene changes thus stops all loads of
+// the previous scene.
+func LoadImageAsync(ctx context.Context, r Renderer, path *url.URL,
+	opts ImageOptions) *AsyncImage {
+	ctx, cancel := context.WithCancel(ctx)
+	ret := &AsyncImage{renderer: r, ctx: ctx, cancel: cancel,
+		result: make(chan decodeResult, 1)}
+	go func() {
+		decoded, err := r.DecodeImageFile(ctx, path, opts)
+		ret.result <- decodeResult{decoded: decoded, err: err}
+	}()
+	return ret
+}
+
+// Poll checks whether loading has finished. If so, it returns ready=true and
+// either the loaded image, which is then owned by the caller, or the error
+// that occurred. Errors include context.Canceled if loading has been
+// cancelled. Once ready, Poll returns the same result on every call.
+//
+// Poll must be called on the render thread, since it creates the texture.
+func (a *AsyncImage) Poll() (img Image, ready bool, err error) {
+	if !a.done {
+		select {
+		case res := <-a.result:
+			a.done = true
+			if res.err == nil {
+				// the context may have been cancelled after decoding finished.
+				res.err = a.ctx.Err()
+			}
+			a.cancel()
+			if res.err != nil {
+				a.err = res.err
+			} else {
+				a.image = a.renderer.UploadImage(res.decoded)
+			}
+		default:
+			if err := a.ctx.Err(); err != nil {
+				a.done, a.err = true, err
+				break
+			}
+			return EmptyImage(), false, nil
+		}
+	}
+	return a.image, true, a.err
+}
+
+// Cancel stops loading the image. An image that has already been returned by
+// Poll is not affected. The worker goroutine may still finish decoding, but
+// its result is discarded and no texture is created.
+func (a *AsyncImage) Cancel() {
+	a.cancel()
+	if !a.done {
+		a.done = true
+		a.err = context.Canceled
+	}
+}
@@ -0,0 +1,57 @@
+package render
+
+import "github.com/QuestScreen/api"
+
+// BlendMode defines how drawn pixels are combined with the pixels already
+// present in the rendering area.
+type BlendMode int
+
+const (
+	// Normal draws the image over the existing content.
+	Normal BlendMode = iota
+	// Additive adds the image's colors to the existing content, which
+	// lightens it. Useful for light sources and glow.
+	Additive
+	// Multiply multiplies the image's colors with the existing content, which
+	// darkens it. Useful for shadows and fog of war.
+	Multiply
+	// Screen inverts both colors, multiplies them and inverts the result,
+	// which lightens the existing content less harshly than Additive.
+	Screen
+	// NumBlendModes is not a valid BlendMode, but used for iteration.
+	NumBlendModes
+)
+
+// DrawOptions defines how an image is drawn. The zero value draws the image
+// unchanged, like DrawImage does.
+//
+// Color adjustments are applied in the order of the fields, before the image
+// is blended into the rendering area.
+type DrawOptions struct {
+	// Blend defines how the image is combined with the existing content.
+	Blend BlendMode
+	// Tint multiplies the image's colors with the tint's color. The tint's
+	// alpha value defines the strength of the effect, so the zero value does
+	// not tint the image.
+	Tint api.RGBA
+	// Grayscale removes colors from the image. 0 leaves the image unchanged,
+	// 1 renders it completely gray.
+	Grayscale float32
+	// Brightness is added to each color channel, -1 renders the image black
+	// and 1 renders it white.
+	Brightness float32
+	// Contrast scales the distance of each color channel to medium gray by
+	// 1+Contrast. -1 renders the image completely gray.
+	Contrast float32
+	// Saturation scales the distance of each color to its gray value by
+	// 1+Saturation. -1 removes colors like a Grayscale of 1 does, positive
+	// values intensify colors.
+	Saturation float32
+}
+
+// DrawWith draws the image to the given rectangular area like Draw, but
+// applies the given options.
+func (i Image) DrawWith(r Renderer, area Rectangle, alpha uint8,
+	opts DrawOptions) {
+	r.DrawImageWith(i, area.Transformation(), alpha, opts)
+}
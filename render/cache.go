@@ -0,0 +1,175 @@
+package render
+
+import (
+	"container/list"
+	"net/url"
+	"sort"
+
+	"github.com/QuestScreen/api/resources"
+)
+
+// ImageCache loads images via a Renderer and shares them between users.
+//
+// Each image is identified by its location and the scaleDownToOutput flag.
+// Load returns the cached image if it exists and increases its reference
+// count; Release decreases it. Images that are not referenced anymore are kept
+// in memory until the cache exceeds its budget, at which point the least
+// recently released images are freed. Thus, switching between scenes that use
+// the same images does not require loading them again.
+//
+// Like the Renderer, an ImageCache must only be used from the render thread.
+type ImageCache struct {
+	renderer Renderer
+	budget   int64
+	used     int64
+	entries  map[cacheKey]*cacheEntry
+	byID     map[uint32]*cacheEntry
+	// unused holds the entries without references, least recently released
+	// first.
+	unused list.List
+}
+
+type cacheKey struct {
+	location  string
+	scaleDown bool
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	image Image
+	refs  int
+	bytes int64
+	// elem is the entry's element in the unused list, nil if it is referenced.
+	elem *list.Element
+}
+
+// CachedImage describes an image held by an ImageCache.
+type CachedImage struct {
+	Location   string
+	ScaleDown  bool
+	References int
+	// Bytes is the estimated size of the image's texture.
+	Bytes int64
+}
+
+// NewImageCache creates an empty cache that loads images with the given
+// renderer. budget is the number of texture bytes the cache may hold before
+// it frees unreferenced images.
+func NewImageCache(r Renderer, budget int64) *ImageCache {
+	return &ImageCache{renderer: r, budget: budget,
+		entries: make(map[cacheKey]*cacheEntry),
+		byID:    make(map[uint32]*cacheEntry)}
+}
+
+// textureBytes estimates the memory used by the texture of the given image.
+func textureBytes(i Image) int64 {
+	return int64(i.Width) * int64(i.Height) * 4
+}
+
+// Load returns the image at the given resource's location, loading it if it
+// is not cached, and increases its reference count. Each successful call must
+// be paired with a call to Release.
+func (c *ImageCache) Load(res resources.Resource,
+	scaleDownToOutput bool) (Image, error) {
+	return c.LoadURL(res.Location, scaleDownToOutput)
+}
+
+// LoadURL is like Load, but takes a URL instead of a resource.
+func (c *ImageCache) LoadURL(path *url.URL,
+	scaleDownToOutput bool) (Image, error) {
+	key := cacheKey{location: path.String(), scaleDown: scaleDownToOutput}
+	if e, ok := c.entries[key]; ok {
+		if e.elem != nil {
+			c.unused.Remove(e.elem)
+			e.elem = nil
+		}
+		e.refs++
+		return e.image, nil
+	}
+	image, err := c.renderer.LoadImageFile(path, scaleDownToOutput)
+	if err != nil {
+		return image, err
+	}
+	e := &cacheEntry{key: key, image: image, refs: 1,
+		bytes: textureBytes(image)}
+	c.entries[key] = e
+	c.byID[image.TextureID] = e
+	c.used += e.bytes
+	c.trim()
+	return image, nil
+}
+
+// Release decreases the reference count of the given image, which must have
+// been returned by Load, and sets it to be the empty image. Images unknown to
+// the cache are ignored. If the image is not referenced anymore, it may be
+// freed once the cache exceeds its budget.
+func (c *ImageCache) Release(i *Image) {
+	if i.IsEmpty() {
+		return
+	}
+	e, ok := c.byID[i.TextureID]
+	if !ok || e.refs == 0 {
+		return
+	}
+	*i = EmptyImage()
+	e.refs--
+	if e.refs == 0 {
+		e.elem = c.unused.PushBack(e)
+		c.trim()
+	}
+}
+
+func (c *ImageCache) free(e *cacheEntry) {
+	c.unused.Remove(e.elem)
+	delete(c.entries, e.key)
+	delete(c.byID, e.image.TextureID)
+	c.used -= e.bytes
+	c.renderer.FreeImage(&e.image)
+}
+
+// trim frees the least recently released images until the cache fits into its
+// budget or no unreferenced images are left.
+func (c *ImageCache) trim() {
+	for c.used > c.budget && c.unused.Len() > 0 {
+		c.free(c.unused.Front().Value.(*cacheEntry))
+	}
+}
+
+// Evict frees all images that are not referenced.
+func (c *ImageCache) Evict() {
+	for c.unused.Len() > 0 {
+		c.free(c.unused.Front().Value.(*cacheEntry))
+	}
+}
+
+// SetBudget changes the budget of the cache, freeing unreferenced images if
+// necessary.
+func (c *ImageCache) SetBudget(budget int64) {
+	c.budget = budget
+	c.trim()
+}
+
+// Used returns the number of texture bytes currently held by the cache,
+// including referenced images. Referenced images are never freed, so this may
+// exceed the budget.
+func (c *ImageCache) Used() int64 {
+	return c.used
+}
+
+// Images returns all images held by the cache, ordered by location. Images
+// that are still referenced after their users have been destroyed indicate
+// missing calls to Release.
+func (c *ImageCache) Images() []CachedImage {
+	ret := make([]CachedImage, 0, len(c.entries))
+	for _, e := range c.entries {
+		ret = append(ret, CachedImage{Location: e.key.location,
+			ScaleDown: e.key.scaleDown, References: e.refs, Bytes: e.bytes})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Location != ret[j].Location {
+			return ret[i].Location < ret[j].Location
+		}
+		return !ret[i].ScaleDown && ret[j].ScaleDown
+	})
+	return ret
+}
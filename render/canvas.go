@@ -1,5 +1,7 @@
 package render
 
+import "github.com/QuestScreen/api"
+
 // Directions is a bitset of directions.
 type Directions uint8
 
@@ -18,6 +20,53 @@ const (
 	Everywhere = North | East | South | West
 )
 
+// Border describes the border at one edge of a canvas.
+type Border struct {
+	// Width of the border in pixels. A border with a Width of 0 is not drawn.
+	Width int32
+	Color api.RGBA
+}
+
+// Borders describes the border at each edge of a canvas.
+//
+// The top and bottom borders span the whole width of the canvas, the left and
+// right borders are drawn between them.
+type Borders struct {
+	Top, Right, Bottom, Left Border
+}
+
+// UniformBorders returns Borders with the same width and color at each edge.
+func UniformBorders(width int32, color api.RGBA) Borders {
+	b := Border{Width: width, Color: color}
+	return Borders{Top: b, Right: b, Bottom: b, Left: b}
+}
+
+// DirectionalBorders returns Borders with the given width and color at each
+// of the given directions, and no border elsewhere.
+func DirectionalBorders(d Directions, width int32, color api.RGBA) Borders {
+	var ret Borders
+	b := Border{Width: width, Color: color}
+	if d&North != 0 {
+		ret.Top = b
+	}
+	if d&East != 0 {
+		ret.Right = b
+	}
+	if d&South != 0 {
+		ret.Bottom = b
+	}
+	if d&West != 0 {
+		ret.Left = b
+	}
+	return ret
+}
+
+// Insets returns the width of each border as Insets.
+func (b Borders) Insets() Insets {
+	return Insets{Top: b.Top.Width, Right: b.Right.Width,
+		Bottom: b.Bottom.Width, Left: b.Left.Width}
+}
+
 // Canvas is a facility to render content into a rectangular canvas.
 // use it to pre-render content into a texture you can later copy to the
 // renderer when rendering a scene.
@@ -0,0 +1,458 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EasingFunction selects the base function of an Easing.
+type EasingFunction int
+
+const (
+	// LinearEasing does not modify the progress.
+	LinearEasing EasingFunction = iota
+	// QuadEasing is based on x².
+	QuadEasing
+	// CubicEasing is based on x³.
+	CubicEasing
+	// QuartEasing is based on x⁴.
+	QuartEasing
+	// SineEasing is based on a quarter sine wave.
+	SineEasing
+	// ExpoEasing is based on 2^(10x-10).
+	ExpoEasing
+	// BackEasing moves slightly backwards before moving forward.
+	BackEasing
+	// ElasticEasing oscillates with increasing amplitude.
+	ElasticEasing
+	// BounceEasing bounces like a ball dropped on the floor.
+	BounceEasing
+	// BezierEasing is a CSS-style cubic bezier curve defined by the control
+	// points of an Easing.
+	BezierEasing
+	// SpringEasing simulates a spring with the damping of an Easing.
+	SpringEasing
+	// NumEasingFunctions is not a valid EasingFunction, but used for iteration.
+	NumEasingFunctions
+)
+
+var easingFunctionNames = [NumEasingFunctions]string{"linear", "quad",
+	"cubic", "quart", "sine", "expo", "back", "elastic", "bounce",
+	"cubic-bezier", "spring"}
+
+// EasingMode defines to which end of the transition an EasingFunction is
+// applied. It is ignored for LinearEasing, BezierEasing and SpringEasing.
+type EasingMode int
+
+const (
+	// EaseIn applies the function to the start of the transition.
+	EaseIn EasingMode = iota
+	// EaseOut applies the function to the end of the transition.
+	EaseOut
+	// EaseInOut applies the function to both ends of the transition.
+	EaseInOut
+)
+
+var easingModeNames = [...]string{"ease-in-", "ease-out-", "ease-in-out-"}
+
+// Easing describes a curve that maps the progress of a transition, which is a
+// value between 0.0 and 1.0, to the progress of the animated value.
+//
+// The zero value is a linear easing. Easings are (de)serialized as strings,
+// which consist of the function followed by optional modifiers, separated by
+// spaces:
+//
+//	linear
+//	ease-in-quad, ease-out-bounce, ease-in-out-elastic, …
+//	cubic-bezier(0.25, 0.1, 0.25, 1.0)
+//	spring(0.3)
+//	ease-out-back reverse
+//	ease-in-out-sine ping-pong repeat(3)
+type Easing struct {
+	Function EasingFunction
+	Mode     EasingMode
+	// X1, Y1, X2, Y2 are the control points of BezierEasing, like in CSS'
+	// cubic-bezier(). X1 and X2 must be between 0.0 and 1.0.
+	X1, Y1, X2, Y2 float32
+	// Damping is the damping ratio of SpringEasing. Values below 1.0 let the
+	// spring oscillate, with smaller values causing more oscillation; values
+	// of 1.0 and above do not oscillate. Values <= 0, including the zero
+	// value, are treated as 1.0.
+	Damping float32
+	// Reverse plays the curve backwards, from 1.0 to 0.0.
+	Reverse bool
+	// PingPong plays the curve forwards in the first half of the transition
+	// and backwards in the second half.
+	PingPong bool
+	// Repeat plays the curve the given number of times during the transition.
+	// 0 and 1 both play it once.
+	Repeat int
+}
+
+// At returns the eased progress for the given progress of the transition,
+// which should be between 0.0 and 1.0. The result may exceed that range for
+// overshooting functions like BackEasing, ElasticEasing and SpringEasing.
+//
+// At can be used as animation.Curve.
+func (e Easing) At(progress float32) float32 {
+	x := float64(progress)
+	if x < 0 {
+		x = 0
+	} else if x > 1 {
+		x = 1
+	}
+	if e.Reverse {
+		x = 1 - x
+	}
+	if e.Repeat > 1 {
+		x *= float64(e.Repeat)
+		if x != math.Floor(x) || x == 0 {
+			x -= math.Floor(x)
+		} else {
+			x = 1
+		}
+	}
+	if e.PingPong {
+		x = 1 - math.Abs(1-2*x)
+	}
+	return float32(e.apply(x))
+}
+
+func (e Easing) apply(x float64) float64 {
+	switch e.Function {
+	case LinearEasing:
+		return x
+	case BezierEasing:
+		return bezier(float64(e.X1), float64(e.Y1), float64(e.X2),
+			float64(e.Y2), x)
+	case SpringEasing:
+		return spring(float64(e.damping()), x)
+	}
+	if e.Function < 0 || e.Function >= NumEasingFunctions {
+		return x
+	}
+	in := easeIn[e.Function]
+	switch e.Mode {
+	case EaseOut:
+		return 1 - in(1-x)
+	case EaseInOut:
+		if x < 0.5 {
+			return in(2*x) / 2
+		}
+		return 1 - in(2-2*x)/2
+	default:
+		return in(x)
+	}
+}
+
+// damping returns the damping ratio SpringEasing uses.
+func (e Easing) damping() float32 {
+	if !(e.Damping > 0) {
+		return 1
+	}
+	return e.Damping
+}
+
+const backOvershoot = 1.70158
+
+var easeIn = [NumEasingFunctions]func(x float64) float64{
+	QuadEasing:  func(x float64) float64 { return x * x },
+	CubicEasing: func(x float64) float64 { return x * x * x },
+	QuartEasing: func(x float64) float64 { return x * x * x * x },
+	SineEasing:  func(x float64) float64 { return 1 - math.Cos(x*math.Pi/2) },
+	ExpoEasing: func(x float64) float64 {
+		if x == 0 {
+			return 0
+		}
+		return math.Pow(2, 10*x-10)
+	},
+	BackEasing: func(x float64) float64 {
+		return (backOvershoot+1)*x*x*x - backOvershoot*x*x
+	},
+	ElasticEasing: func(x float64) float64 {
+		if x == 0 || x == 1 {
+			return x
+		}
+		return -math.Pow(2, 10*x-10) * math.Sin((10*x-10.75)*2*math.Pi/3)
+	},
+	BounceEasing: func(x float64) float64 {
+		return 1 - bounceOut(1-x)
+	},
+}
+
+func bounceOut(x float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case x < 1/d:
+		return n * x * x
+	case x < 2/d:
+		x -= 1.5 / d
+		return n*x*x + 0.75
+	case x < 2.5/d:
+		x -= 2.25 / d
+		return n*x*x + 0.9375
+	default:
+		x -= 2.625 / d
+		return n*x*x + 0.984375
+	}
+}
+
+// bezier solves the cubic bezier curve with the control points (0,0),
+// (x1,y1), (x2,y2), (1,1) for the given x and returns the corresponding y.
+func bezier(x1, y1, x2, y2, x float64) float64 {
+	coord := func(p1, p2, t float64) float64 {
+		return ((1-3*p2+3*p1)*t+(3*p2-6*p1))*t*t + 3*p1*t
+	}
+	slope := func(p1, p2, t float64) float64 {
+		return 3*(1-3*p2+3*p1)*t*t + 2*(3*p2-6*p1)*t + 3*p1
+	}
+	// Newton's method converges quickly for most curves ...
+	t := x
+	for i := 0; i < 8; i++ {
+		diff := coord(x1, x2, t) - x
+		if math.Abs(diff) < 1e-7 {
+			return coord(y1, y2, t)
+		}
+		s := slope(x1, x2, t)
+		if math.Abs(s) < 1e-6 {
+			break
+		}
+		t -= diff / s
+	}
+	// ... and bisection takes care of the rest, since x(t) is monotonic for
+	// x1, x2 in [0, 1].
+	lo, hi := 0.0, 1.0
+	t = x
+	for i := 0; i < 64 && hi-lo > 1e-7; i++ {
+		if coord(x1, x2, t) < x {
+			lo = t
+		} else {
+			hi = t
+		}
+		t = (lo + hi) / 2
+	}
+	return coord(y1, y2, t)
+}
+
+// springSettle is the remaining amplitude of a spring at the end of the
+// transition; the spring's frequency is chosen accordingly.
+const springSettle = 0.001
+
+// spring returns the position of a damped spring that is released at x=0 and
+// settles at the target by x=1.
+func spring(damping, x float64) float64 {
+	if x >= 1 {
+		return 1
+	}
+	if damping >= 1 {
+		// critically damped: e^(-ωx)(1+ωx) reaches springSettle at ωx ≈ 9.23
+		const omega = 9.23
+		return 1 - math.Exp(-omega*x)*(1+omega*x)
+	}
+	omega := -math.Log(springSettle) / damping
+	dampedOmega := omega * math.Sqrt(1-damping*damping)
+	return 1 - math.Exp(-damping*omega*x)*(math.Cos(dampedOmega*x)+
+		damping*omega/dampedOmega*math.Sin(dampedOmega*x))
+}
+
+func formatEasingFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+// String returns the textual representation of the easing as described in
+// the documentation of Easing.
+func (e Easing) String() string {
+	var b strings.Builder
+	switch e.Function {
+	case LinearEasing:
+		b.WriteString("linear")
+	case BezierEasing:
+		fmt.Fprintf(&b, "cubic-bezier(%s, %s, %s, %s)", formatEasingFloat(e.X1),
+			formatEasingFloat(e.Y1), formatEasingFloat(e.X2),
+			formatEasingFloat(e.Y2))
+	case SpringEasing:
+		fmt.Fprintf(&b, "spring(%s)", formatEasingFloat(e.damping()))
+	default:
+		if e.Function < 0 || e.Function >= NumEasingFunctions ||
+			e.Mode < EaseIn || e.Mode > EaseInOut {
+			return fmt.Sprintf("invalid(%d, %d)", e.Function, e.Mode)
+		}
+		b.WriteString(easingModeNames[e.Mode])
+		b.WriteString(easingFunctionNames[e.Function])
+	}
+	if e.Reverse {
+		b.WriteString(" reverse")
+	}
+	if e.PingPong {
+		b.WriteString(" ping-pong")
+	}
+	if e.Repeat > 1 {
+		fmt.Fprintf(&b, " repeat(%d)", e.Repeat)
+	}
+	return b.String()
+}
+
+// validate returns an error if the easing cannot be represented as string.
+func (e Easing) validate() error {
+	switch e.Function {
+	case LinearEasing, SpringEasing:
+	case BezierEasing:
+		if !(e.X1 >= 0 && e.X1 <= 1 && e.X2 >= 0 && e.X2 <= 1) {
+			return errors.New("x values of cubic-bezier must be in [0..1]")
+		}
+	default:
+		if e.Function < 0 || e.Function >= NumEasingFunctions {
+			return fmt.Errorf("invalid easing function: %d", e.Function)
+		}
+		if e.Mode < EaseIn || e.Mode > EaseInOut {
+			return fmt.Errorf("invalid easing mode: %d", e.Mode)
+		}
+	}
+	return nil
+}
+
+// parseArgs parses the arguments of a function call like `name(a, b)`.
+func parseArgs(call, name string, count int) ([]float32, error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(call, name+"("), ")")
+	if len(inner) == len(call)-len(name)-1 {
+		return nil, fmt.Errorf("missing ')' in %s", call)
+	}
+	parts := strings.Split(inner, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("%s requires %d arguments, got %d", name, count,
+			len(parts))
+	}
+	ret := make([]float32, count)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument to %s: %s", name,
+				strings.TrimSpace(part))
+		}
+		ret[i] = float32(v)
+	}
+	return ret, nil
+}
+
+// splitEasing splits the given string at spaces that are not inside
+// parentheses.
+func splitEasing(repr string) []string {
+	var ret []string
+	depth, start := 0, -1
+	for i, c := range repr {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ' ' && depth == 0:
+			if start != -1 {
+				ret = append(ret, repr[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start == -1 {
+			start = i
+		}
+	}
+	if start != -1 {
+		ret = append(ret, repr[start:])
+	}
+	return ret
+}
+
+// FromRepr loads an easing from its textual representation as described in
+// the documentation of Easing. On error, e is not modified.
+func (e *Easing) FromRepr(repr string) error {
+	parts := splitEasing(repr)
+	if len(parts) == 0 {
+		return errors.New("missing easing function")
+	}
+	var ret Easing
+	function := parts[0]
+	switch {
+	case function == "linear":
+	case strings.HasPrefix(function, "cubic-bezier("):
+		args, err := parseArgs(function, "cubic-bezier", 4)
+		if err != nil {
+			return err
+		}
+		if args[0] < 0 || args[0] > 1 || args[2] < 0 || args[2] > 1 {
+			return errors.New("x values of cubic-bezier must be in [0..1]")
+		}
+		ret.Function = BezierEasing
+		ret.X1, ret.Y1, ret.X2, ret.Y2 = args[0], args[1], args[2], args[3]
+	case strings.HasPrefix(function, "spring("):
+		args, err := parseArgs(function, "spring", 1)
+		if err != nil {
+			return err
+		}
+		if args[0] <= 0 {
+			return errors.New("damping of spring must be greater than 0")
+		}
+		ret.Function = SpringEasing
+		ret.Damping = args[0]
+	default:
+		found := false
+		// try longest prefix first so that ease-in-out- is not taken for ease-in-
+		for mode := EaseInOut; mode >= EaseIn && !found; mode-- {
+			if !strings.HasPrefix(function, easingModeNames[mode]) {
+				continue
+			}
+			name := function[len(easingModeNames[mode]):]
+			for f := QuadEasing; f <= BounceEasing; f++ {
+				if easingFunctionNames[f] == name {
+					ret.Function, ret.Mode, found = f, mode, true
+					break
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown easing function: %s", function)
+		}
+	}
+	for _, modifier := range parts[1:] {
+		switch {
+		case modifier == "reverse":
+			ret.Reverse = true
+		case modifier == "ping-pong":
+			ret.PingPong = true
+		case strings.HasPrefix(modifier, "repeat("):
+			args, err := parseArgs(modifier, "repeat", 1)
+			if err != nil {
+				return err
+			}
+			if args[0] < 1 || args[0] != float32(int(args[0])) {
+				return errors.New("repeat count must be a positive integer")
+			}
+			ret.Repeat = int(args[0])
+		default:
+			return fmt.Errorf("unknown easing modifier: %s", modifier)
+		}
+	}
+	*e = ret
+	return nil
+}
+
+// UnmarshalJSON loads a JSON string as textual representation of an easing.
+func (e *Easing) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return e.FromRepr(s)
+}
+
+// MarshalJSON represents the easing as JSON string containing its textual
+// representation. Returns an error for invalid easings.
+func (e Easing) MarshalJSON() ([]byte, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(e.String())
+}
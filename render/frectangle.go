@@ -0,0 +1,148 @@
+package render
+
+import (
+	"math"
+
+	"github.com/QuestScreen/api"
+)
+
+// FRectangle is a rectangle with float coordinates. It provides the same API
+// as Rectangle.
+//
+// Use FRectangle for animated layouts: Rectangle rounds to whole pixels after
+// each operation, which makes slow movement and scaling visibly jitter.
+// Static layouts should use Rectangle.
+type FRectangle struct {
+	// coordinate of the lower left corner
+	X, Y          float32
+	Width, Height float32
+}
+
+// Float returns the rectangle as FRectangle. The conversion is lossless.
+func (r Rectangle) Float() FRectangle {
+	return FRectangle{X: float32(r.X), Y: float32(r.Y),
+		Width: float32(r.Width), Height: float32(r.Height)}
+}
+
+func round(f float32) int32 {
+	return int32(math.Round(float64(f)))
+}
+
+// Round returns the rectangle with its edges rounded to whole pixels.
+// Converting a Rectangle to FRectangle and back yields the original
+// Rectangle.
+//
+// Width and Height are calculated from the rounded edges, so that rectangles
+// sharing an edge still share it after rounding.
+func (r FRectangle) Round() Rectangle {
+	ret := Rectangle{X: round(r.X), Y: round(r.Y)}
+	ret.Width = round(r.X+r.Width) - ret.X
+	ret.Height = round(r.Y+r.Height) - ret.Y
+	return ret
+}
+
+// Translation returns the transformation needed to move an object centered on
+// the origin to the center of the rectangle.
+func (r FRectangle) Translation() Transform {
+	return Identity().Translate(r.X+r.Width/2.0, r.Y+r.Height/2.0)
+}
+
+// Transformation returns the transformation needed to transform a square with
+// edge length of 1.0 centered around the origin to the subject rectangle.
+func (r FRectangle) Transformation() Transform {
+	return r.Translation().Scale(r.Width, r.Height)
+}
+
+// Move moves the rectangle by the given delta
+func (r FRectangle) Move(dx, dy float32) FRectangle {
+	return FRectangle{r.X + dx, r.Y + dy, r.Width, r.Height}
+}
+
+// Shrink removes dw from the rectangles width and dh from its height,
+// repositioning it so that the center stays the same.
+func (r FRectangle) Shrink(dw, dh float32) FRectangle {
+	return FRectangle{r.X + dw/2, r.Y + dh/2, r.Width - dw, r.Height - dh}
+}
+
+// Scale scales the rectangle's width and height by the given factor,
+// repositioning it so that the center stays the same.
+func (r FRectangle) Scale(factor float32) FRectangle {
+	ret := FRectangle{Width: r.Width * factor, Height: r.Height * factor}
+	ret.X = r.X + (r.Width-ret.Width)/2
+	ret.Y = r.Y + (r.Height-ret.Height)/2
+	return ret
+}
+
+// Position returns a rectangle with the given width and height, which is
+// position in the current rectangle according to the given flags.
+//
+// giving HStretch and VStretch will override the given width and height
+// respectively, the other positioning flags will only set the position.
+func (r FRectangle) Position(width, height float32, horiz HAlign,
+	vert VAlign) FRectangle {
+	ret := FRectangle{Width: width, Height: height}
+	switch horiz {
+	case Left:
+		ret.X = r.X
+	case Center:
+		ret.X = r.X + (r.Width-width)/2
+	case Right:
+		ret.X = r.X + r.Width - width
+	case HStretch:
+		ret.X = r.X
+		ret.Width = r.Width
+	}
+	switch vert {
+	case Top:
+		ret.Y = r.Y + r.Height - height
+	case Middle:
+		ret.Y = r.Y + (r.Height-height)/2
+	case Bottom:
+		ret.Y = r.Y
+	case VStretch:
+		ret.Y = r.Y
+		ret.Height = r.Height
+	}
+	return ret
+}
+
+// Carve removes a rectangle of the given length starting at the given edge
+// from the current rectangle.
+//
+// edge must be North, East, South or West. The carved rectangle is returned
+// as `carved`, the remaining rectangle as `rest`
+func (r FRectangle) Carve(edge Directions,
+	length float32) (carved FRectangle, rest FRectangle) {
+	switch edge {
+	case North:
+		rest = FRectangle{X: r.X, Y: r.Y, Width: r.Width, Height: r.Height - length}
+		carved = FRectangle{X: r.X, Y: r.Y + rest.Height, Width: r.Width, Height: length}
+	case East:
+		rest = FRectangle{X: r.X, Y: r.Y, Width: r.Width - length, Height: r.Height}
+		carved = FRectangle{X: r.X + rest.Width, Y: r.Y, Width: length, Height: r.Height}
+	case South:
+		rest = FRectangle{X: r.X, Y: r.Y + length, Width: r.Width, Height: r.Height - length}
+		carved = FRectangle{X: r.X, Y: r.Y, Width: r.Width, Height: length}
+	case West:
+		rest = FRectangle{X: r.X + length, Y: r.Y, Width: r.Width - length, Height: r.Height}
+		carved = FRectangle{X: r.X, Y: r.Y, Width: length, Height: r.Height}
+	default:
+		panic("illegal edge (must be North, East, South or West)")
+	}
+	return
+}
+
+// Interpolate returns the rectangle between r and target at the given
+// progress, which is 0.0 for r and 1.0 for target. Position and size are
+// interpolated separately; progress values outside of [0..1] extrapolate.
+func (r FRectangle) Interpolate(target FRectangle, progress float32) FRectangle {
+	return FRectangle{X: r.X + (target.X-r.X)*progress,
+		Y:      r.Y + (target.Y-r.Y)*progress,
+		Width:  r.Width + (target.Width-r.Width)*progress,
+		Height: r.Height + (target.Height-r.Height)*progress}
+}
+
+// Fill fills the rectangle with the given color.
+func (r FRectangle) Fill(renderer Renderer, color api.RGBA) {
+	renderer.FillRect(r.Transformation(), color)
+}
@@ -0,0 +1,253 @@
+package render
+
+// Insets defines a length for each edge of a rectangle, e.g. for padding.
+type Insets struct {
+	Top, Right, Bottom, Left int32
+}
+
+// UniformInsets returns Insets with the given length at each edge.
+func UniformInsets(length int32) Insets {
+	return Insets{Top: length, Right: length, Bottom: length, Left: length}
+}
+
+// Inset removes the given insets from the rectangle's edges.
+func (r Rectangle) Inset(i Insets) Rectangle {
+	return Rectangle{X: r.X + i.Left, Y: r.Y + i.Bottom,
+		Width: r.Width - i.Left - i.Right, Height: r.Height - i.Top - i.Bottom}
+}
+
+// Outset adds the given insets to the rectangle's edges. It is the inverse of
+// Inset.
+func (r Rectangle) Outset(i Insets) Rectangle {
+	return Rectangle{X: r.X - i.Left, Y: r.Y - i.Bottom,
+		Width: r.Width + i.Left + i.Right, Height: r.Height + i.Top + i.Bottom}
+}
+
+// split divides length minus the gaps into parts according to the given
+// weights and returns the offset and size of each part. Parts are rounded so
+// that they cover the available length exactly. If all weights are zero, the
+// parts are equally sized; if the gaps leave no space, all parts are empty.
+// Panics if a weight is negative.
+func split(length, gap int32, weights []float32) (offsets, sizes []int32) {
+	if len(weights) == 0 {
+		return nil, nil
+	}
+	var total float32
+	for _, w := range weights {
+		if !(w >= 0) {
+			panic("weights must not be negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		weights = equalWeights(len(weights))
+		total = float32(len(weights))
+	}
+	available := float32(length - gap*int32(len(weights)-1))
+	if available < 0 {
+		available = 0
+	}
+	offsets = make([]int32, len(weights))
+	sizes = make([]int32, len(weights))
+	var sum float32
+	var start int32
+	for i, w := range weights {
+		sum += w
+		end := int32(available + 0.5)
+		if total > 0 && i < len(weights)-1 {
+			end = int32(available*sum/total + 0.5)
+		}
+		offsets[i] = start + gap*int32(i)
+		sizes[i] = end - start
+		start = end
+	}
+	return
+}
+
+func equalWeights(n int) []float32 {
+	if n <= 0 {
+		return nil
+	}
+	ret := make([]float32, n)
+	for i := range ret {
+		ret[i] = 1
+	}
+	return ret
+}
+
+// Rows splits the rectangle into n rows of equal height, separated by the
+// given gap. The rows are returned from top to bottom. Returns no rows if n
+// is not positive.
+func (r Rectangle) Rows(n int, gap int32) []Rectangle {
+	return r.WeightedRows(gap, equalWeights(n)...)
+}
+
+// WeightedRows splits the rectangle into one row per weight, separated by the
+// given gap. Each row's height is proportional to its weight. The rows are
+// returned from top to bottom. Weights must not be negative; if all are zero,
+// the rows have equal height.
+func (r Rectangle) WeightedRows(gap int32, weights ...float32) []Rectangle {
+	offsets, sizes := split(r.Height, gap, weights)
+	ret := make([]Rectangle, len(weights))
+	for i := range ret {
+		ret[i] = Rectangle{X: r.X, Y: r.Y + r.Height - offsets[i] - sizes[i],
+			Width: r.Width, Height: sizes[i]}
+	}
+	return ret
+}
+
+// Columns splits the rectangle into n columns of equal width, separated by
+// the given gap. The columns are returned from left to right. Returns no
+// columns if n is not positive.
+func (r Rectangle) Columns(n int, gap int32) []Rectangle {
+	return r.WeightedColumns(gap, equalWeights(n)...)
+}
+
+// WeightedColumns splits the rectangle into one column per weight, separated
+// by the given gap. Each column's width is proportional to its weight. The
+// columns are returned from left to right. Weights must not be negative; if
+// all are zero, the columns have equal width.
+func (r Rectangle) WeightedColumns(gap int32, weights ...float32) []Rectangle {
+	offsets, sizes := split(r.Width, gap, weights)
+	ret := make([]Rectangle, len(weights))
+	for i := range ret {
+		ret[i] = Rectangle{X: r.X + offsets[i], Y: r.Y, Width: sizes[i],
+			Height: r.Height}
+	}
+	return ret
+}
+
+// Grid divides an area into equally sized cells. Rows are counted from the
+// top, columns from the left.
+type Grid struct {
+	Area          Rectangle
+	Columns, Rows int
+	// Gap is the space between two neighbouring cells.
+	Gap int32
+}
+
+// Cell returns the cell at the given column and row. Returns an empty
+// Rectangle if column or row is outside of the grid.
+func (g Grid) Cell(column, row int) Rectangle {
+	if column < 0 || column >= g.Columns || row < 0 || row >= g.Rows {
+		return Rectangle{}
+	}
+	xOffsets, widths := split(g.Area.Width, g.Gap, equalWeights(g.Columns))
+	yOffsets, heights := split(g.Area.Height, g.Gap, equalWeights(g.Rows))
+	return Rectangle{X: g.Area.X + xOffsets[column],
+		Y:     g.Area.Y + g.Area.Height - yOffsets[row] - heights[row],
+		Width: widths[column], Height: heights[row]}
+}
+
+// CellAt returns the column and row of the cell containing the given point.
+// ok is false if the point is outside of the area or inside a gap.
+func (g Grid) CellAt(x, y int32) (column, row int, ok bool) {
+	column, row = -1, -1
+	xOffsets, widths := split(g.Area.Width, g.Gap, equalWeights(g.Columns))
+	for i := range xOffsets {
+		if rx := x - g.Area.X; rx >= xOffsets[i] && rx < xOffsets[i]+widths[i] {
+			column = i
+			break
+		}
+	}
+	yOffsets, heights := split(g.Area.Height, g.Gap, equalWeights(g.Rows))
+	for i := range yOffsets {
+		if ry := g.Area.Y + g.Area.Height - 1 - y; ry >= yOffsets[i] &&
+			ry < yOffsets[i]+heights[i] {
+			row = i
+			break
+		}
+	}
+	return column, row, column != -1 && row != -1
+}
+
+// Empty returns true iff the rectangle has no area.
+func (r Rectangle) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Contains returns true iff the given point is inside the rectangle. The
+// lower and left edges are part of the rectangle, the upper and right edges
+// are not.
+func (r Rectangle) Contains(x, y int32) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+func min32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Intersect returns the area covered by both rectangles. If they do not
+// overlap, the returned rectangle is Empty.
+func (r Rectangle) Intersect(other Rectangle) Rectangle {
+	x, y := max32(r.X, other.X), max32(r.Y, other.Y)
+	ret := Rectangle{X: x, Y: y,
+		Width:  min32(r.X+r.Width, other.X+other.Width) - x,
+		Height: min32(r.Y+r.Height, other.Y+other.Height) - y}
+	if ret.Empty() {
+		return Rectangle{X: x, Y: y}
+	}
+	return ret
+}
+
+// Union returns the smallest rectangle containing both rectangles. Empty
+// rectangles are ignored.
+func (r Rectangle) Union(other Rectangle) Rectangle {
+	if r.Empty() {
+		return other
+	} else if other.Empty() {
+		return r
+	}
+	x, y := min32(r.X, other.X), min32(r.Y, other.Y)
+	return Rectangle{X: x, Y: y,
+		Width:  max32(r.X+r.Width, other.X+other.Width) - x,
+		Height: max32(r.Y+r.Height, other.Y+other.Height) - y}
+}
+
+// FitMode defines how content is scaled into an area with a different
+// aspect ratio.
+type FitMode int
+
+const (
+	// Contain scales the content to the largest size that fits completely into
+	// the area. The area may not be covered completely.
+	Contain FitMode = iota
+	// Cover scales the content to the smallest size that covers the area
+	// completely. Parts of the content may lie outside of the area.
+	Cover
+	// Exact scales the content to the area's size, ignoring its aspect ratio.
+	Exact
+)
+
+// Fit returns a rectangle with the aspect ratio of the given width and
+// height, scaled according to mode and centered in the current rectangle.
+// For Exact, the current rectangle is returned.
+func (r Rectangle) Fit(width, height int32, mode FitMode) Rectangle {
+	if mode == Exact {
+		return r
+	}
+	if width <= 0 || height <= 0 {
+		return Rectangle{X: r.X + r.Width/2, Y: r.Y + r.Height/2}
+	}
+	// compare r.Width/r.Height to width/height without division
+	wider := int64(r.Width)*int64(height) > int64(r.Height)*int64(width)
+	var w, h int32
+	if wider == (mode == Contain) {
+		h = r.Height
+		w = int32((int64(width)*int64(h) + int64(height)/2) / int64(height))
+	} else {
+		w = r.Width
+		h = int32((int64(height)*int64(w) + int64(width)/2) / int64(width))
+	}
+	return r.Position(w, h, Center, Middle)
+}
@@ -0,0 +1,200 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []Rectangle
+		want []Rectangle
+	}{
+		{"rows with gap", Rectangle{Width: 100, Height: 50}.Rows(3, 5),
+			[]Rectangle{{0, 37, 100, 13}, {0, 18, 100, 14}, {0, 0, 100, 13}}},
+		{"single row", Rectangle{1, 2, 3, 4}.Rows(1, 10),
+			[]Rectangle{{1, 2, 3, 4}}},
+		{"no rows", Rectangle{Width: 10, Height: 10}.Rows(0, 0), nil},
+		{"negative rows", Rectangle{Width: 10, Height: 10}.Rows(-1, 0), nil},
+		{"columns", Rectangle{10, 20, 100, 30}.Columns(2, 0),
+			[]Rectangle{{10, 20, 50, 30}, {60, 20, 50, 30}}},
+		{"negative columns", Rectangle{Width: 10, Height: 10}.Columns(-3, 2),
+			nil},
+		{"weighted rows", Rectangle{Width: 10, Height: 40}.WeightedRows(0, 1, 1, 2),
+			[]Rectangle{{0, 30, 10, 10}, {0, 20, 10, 10}, {0, 0, 10, 20}}},
+		{"weighted columns", Rectangle{Width: 90, Height: 10}.WeightedColumns(10,
+			1, 3), []Rectangle{{0, 0, 20, 10}, {30, 0, 60, 10}}},
+		{"no weights", Rectangle{Width: 90, Height: 10}.WeightedColumns(10), nil},
+		{"zero weights", Rectangle{Width: 90, Height: 10}.WeightedColumns(0, 0, 0,
+			0), []Rectangle{{0, 0, 30, 10}, {30, 0, 30, 10}, {60, 0, 30, 10}}},
+		{"zero and positive weights", Rectangle{Width: 90, Height: 10}.
+			WeightedColumns(0, 0, 1), []Rectangle{{0, 0, 0, 10}, {0, 0, 90, 10}}},
+		{"gap larger than length", Rectangle{Width: 10, Height: 10}.Columns(3, 20),
+			[]Rectangle{{0, 0, 0, 10}, {20, 0, 0, 10}, {40, 0, 0, 10}}},
+	}
+	for _, tt := range tests {
+		if len(tt.got) != len(tt.want) ||
+			(len(tt.want) > 0 && !reflect.DeepEqual(tt.got, tt.want)) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSplitNegativeWeight(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("WeightedRows with negative weight did not panic")
+		}
+	}()
+	Rectangle{Width: 10, Height: 10}.WeightedRows(0, 1, -1)
+}
+
+func TestGridCell(t *testing.T) {
+	g := Grid{Area: Rectangle{Width: 100, Height: 100}, Columns: 2, Rows: 2,
+		Gap: 10}
+	tests := []struct {
+		column, row int
+		want        Rectangle
+	}{
+		{0, 0, Rectangle{0, 55, 45, 45}},
+		{1, 0, Rectangle{55, 55, 45, 45}},
+		{1, 1, Rectangle{55, 0, 45, 45}},
+		{2, 0, Rectangle{}},
+		{0, 2, Rectangle{}},
+		{-1, 0, Rectangle{}},
+		{0, -1, Rectangle{}},
+	}
+	for _, tt := range tests {
+		if got := g.Cell(tt.column, tt.row); got != tt.want {
+			t.Errorf("Cell(%d, %d) = %v, want %v", tt.column, tt.row, got,
+				tt.want)
+		}
+	}
+	if got := (Grid{Area: g.Area}).Cell(0, 0); got != (Rectangle{}) {
+		t.Errorf("Cell(0, 0) of grid without cells = %v, want empty", got)
+	}
+}
+
+func TestGridCellAt(t *testing.T) {
+	g := Grid{Area: Rectangle{Width: 100, Height: 100}, Columns: 2, Rows: 2,
+		Gap: 10}
+	tests := []struct {
+		x, y        int32
+		column, row int
+		ok          bool
+	}{
+		{10, 90, 0, 0, true},
+		{99, 0, 1, 1, true},
+		{0, 0, 0, 1, true},
+		{50, 50, -1, -1, false},
+		{50, 20, -1, 1, false},
+		{100, 0, -1, 1, false},
+		{-1, 5, -1, 1, false},
+	}
+	for _, tt := range tests {
+		column, row, ok := g.CellAt(tt.x, tt.y)
+		if column != tt.column || row != tt.row || ok != tt.ok {
+			t.Errorf("CellAt(%d, %d) = %d, %d, %v, want %d, %d, %v", tt.x, tt.y,
+				column, row, ok, tt.column, tt.row, tt.ok)
+		}
+	}
+	if _, _, ok := (Grid{Area: g.Area}).CellAt(5, 5); ok {
+		t.Error("CellAt of grid without cells returned ok")
+	}
+}
+
+func TestInsets(t *testing.T) {
+	tests := []struct {
+		r      Rectangle
+		insets Insets
+		want   Rectangle
+	}{
+		{Rectangle{10, 10, 100, 50}, Insets{Top: 1, Right: 2, Bottom: 3, Left: 4},
+			Rectangle{14, 13, 94, 46}},
+		{Rectangle{0, 0, 20, 20}, UniformInsets(5), Rectangle{5, 5, 10, 10}},
+		{Rectangle{0, 0, 20, 20}, Insets{}, Rectangle{0, 0, 20, 20}},
+	}
+	for _, tt := range tests {
+		got := tt.r.Inset(tt.insets)
+		if got != tt.want {
+			t.Errorf("%v.Inset(%v) = %v, want %v", tt.r, tt.insets, got, tt.want)
+		}
+		if back := got.Outset(tt.insets); back != tt.r {
+			t.Errorf("%v.Outset(%v) = %v, want %v", got, tt.insets, back, tt.r)
+		}
+	}
+}
+
+func TestIntersectUnion(t *testing.T) {
+	tests := []struct {
+		a, b                Rectangle
+		intersection, union Rectangle
+	}{
+		{Rectangle{0, 0, 10, 10}, Rectangle{5, 5, 10, 10},
+			Rectangle{5, 5, 5, 5}, Rectangle{0, 0, 15, 15}},
+		{Rectangle{0, 0, 10, 10}, Rectangle{2, 2, 3, 3},
+			Rectangle{2, 2, 3, 3}, Rectangle{0, 0, 10, 10}},
+		{Rectangle{0, 0, 10, 10}, Rectangle{20, 0, 5, 5},
+			Rectangle{20, 0, 0, 0}, Rectangle{0, 0, 25, 10}},
+		{Rectangle{0, 0, 10, 10}, Rectangle{10, 0, 5, 5},
+			Rectangle{10, 0, 0, 0}, Rectangle{0, 0, 15, 10}},
+		{Rectangle{0, 0, 10, 10}, Rectangle{100, 100, 0, 0},
+			Rectangle{100, 100, 0, 0}, Rectangle{0, 0, 10, 10}},
+		{Rectangle{}, Rectangle{1, 2, 3, 4},
+			Rectangle{1, 2, 0, 0}, Rectangle{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Intersect(tt.b); got != tt.intersection {
+			t.Errorf("%v.Intersect(%v) = %v, want %v", tt.a, tt.b, got,
+				tt.intersection)
+		}
+		if got := tt.a.Union(tt.b); got != tt.union {
+			t.Errorf("%v.Union(%v) = %v, want %v", tt.a, tt.b, got, tt.union)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	r := Rectangle{0, 0, 10, 10}
+	tests := []struct {
+		x, y int32
+		want bool
+	}{
+		{0, 0, true},
+		{9, 9, true},
+		{5, 5, true},
+		{10, 5, false},
+		{5, 10, false},
+		{-1, 0, false},
+		{0, -1, false},
+	}
+	for _, tt := range tests {
+		if got := r.Contains(tt.x, tt.y); got != tt.want {
+			t.Errorf("Contains(%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	area := Rectangle{0, 0, 100, 50}
+	tests := []struct {
+		width, height int32
+		mode          FitMode
+		want          Rectangle
+	}{
+		{200, 200, Contain, Rectangle{25, 0, 50, 50}},
+		{200, 200, Cover, Rectangle{0, -25, 100, 100}},
+		{200, 200, Exact, area},
+		{400, 100, Contain, Rectangle{0, 12, 100, 25}},
+		{400, 100, Cover, Rectangle{-50, 0, 200, 50}},
+		{10, 5, Contain, area},
+		{0, 10, Contain, Rectangle{50, 25, 0, 0}},
+	}
+	for _, tt := range tests {
+		if got := area.Fit(tt.width, tt.height, tt.mode); got != tt.want {
+			t.Errorf("Fit(%d, %d, %d) = %v, want %v", tt.width, tt.height,
+				tt.mode, got, tt.want)
+		}
+	}
+}
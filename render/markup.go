@@ -0,0 +1,125 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/QuestScreen/api"
+)
+
+// TextSpan is a part of a text that is rendered with a single font.
+type TextSpan struct {
+	Text string
+	Font api.Font
+}
+
+var markupSizes = map[string]api.FontSize{
+	"Small": api.SmallFont, "Content": api.ContentFont,
+	"Medium": api.MediumFont, "Heading": api.HeadingFont,
+	"Large": api.LargeFont, "Huge": api.HugeFont}
+
+// ParseMarkup parses a text containing inline markup into spans that can be
+// rendered with Renderer.RenderRichText. base is the font used for text
+// outside of any markup.
+//
+// The markup consists of the following tags, which must be properly nested:
+//
+//	[b]bold[/b]
+//	[i]italic[/i]
+//	[color=#rrggbb]colored[/color]     (or #rrggbbaa)
+//	[size=Heading]resized[/size]       (any api.FontSize name)
+//
+// Bold and italic can be combined and yield BoldItalicFont. To write a
+// literal `[`, use `[[`.
+func ParseMarkup(text string, base api.Font) ([]TextSpan, error) {
+	var spans []TextSpan
+	stack := []api.Font{base}
+	var tags []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			spans = append(spans, TextSpan{Text: cur.String(),
+				Font: stack[len(stack)-1]})
+			cur.Reset()
+		}
+	}
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '[')
+		if start == -1 {
+			cur.WriteString(text)
+			break
+		}
+		cur.WriteString(text[:start])
+		text = text[start:]
+		if strings.HasPrefix(text, "[[") {
+			cur.WriteByte('[')
+			text = text[2:]
+			continue
+		}
+		end := strings.IndexByte(text, ']')
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed tag: %s", text)
+		}
+		tag := text[1:end]
+		text = text[end+1:]
+		flush()
+		if strings.HasPrefix(tag, "/") {
+			if len(tags) == 0 || tags[len(tags)-1] != tag[1:] {
+				return nil, fmt.Errorf("unexpected closing tag [%s]", tag)
+			}
+			tags = tags[:len(tags)-1]
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		f := stack[len(stack)-1]
+		name, value := tag, ""
+		if eq := strings.IndexByte(tag, '='); eq != -1 {
+			name, value = tag[:eq], tag[eq+1:]
+		}
+		switch name {
+		case "b":
+			if f.Style == api.RegularFont || f.Style == api.ItalicFont {
+				f.Style++
+			}
+		case "i":
+			if f.Style == api.RegularFont || f.Style == api.BoldFont {
+				f.Style += 2
+			}
+		case "color":
+			if len(value) == 7 {
+				var c api.RGB
+				if err := c.FromHexRepr(value); err != nil {
+					return nil, err
+				}
+				f.Color = c.WithAlpha(255)
+			} else if err := f.Color.FromHexRepr(value); err != nil {
+				return nil, err
+			}
+		case "size":
+			size, ok := markupSizes[value]
+			if !ok {
+				return nil, fmt.Errorf("unknown font size: %s", value)
+			}
+			f.Size = size
+		default:
+			return nil, fmt.Errorf("unknown tag [%s]", tag)
+		}
+		tags = append(tags, name)
+		stack = append(stack, f)
+	}
+	if len(tags) > 0 {
+		return nil, fmt.Errorf("missing closing tag [/%s]", tags[len(tags)-1])
+	}
+	flush()
+	return spans, nil
+}
+
+// RenderMarkup parses the given text with ParseMarkup and renders the result
+// with RenderRichText.
+func RenderMarkup(r Renderer, text string, base api.Font) (Image, error) {
+	spans, err := ParseMarkup(text, base)
+	if err != nil {
+		return EmptyImage(), err
+	}
+	return r.RenderRichText(spans), nil
+}
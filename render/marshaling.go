@@ -0,0 +1,24 @@
+// +build !js
+
+package render
+
+import "gopkg.in/yaml.v3"
+
+// UnmarshalYAML loads the easing from a YAML scalar containing its textual
+// representation.
+func (e *Easing) UnmarshalYAML(value *yaml.Node) error {
+	var repr string
+	if err := value.Decode(&repr); err != nil {
+		return err
+	}
+	return e.FromRepr(repr)
+}
+
+// MarshalYAML maps the easing to its textual representation. Returns an
+// error for invalid easings.
+func (e Easing) MarshalYAML() (interface{}, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return e.String(), nil
+}
@@ -0,0 +1,58 @@
+package render
+
+// PatchMode defines how a part of a NinePatch is resized to fill its area.
+type PatchMode int
+
+const (
+	// Stretch scales the part to the size of its area.
+	Stretch PatchMode = iota
+	// Tile repeats the part in its original size, starting at the top left
+	// corner of its area. The last tile in each direction is cut off.
+	Tile
+)
+
+// NinePatch describes an image that is drawn to an area of arbitrary size
+// without distorting its borders, e.g. a decorative frame.
+//
+// The image is split into nine parts by the Insets: The corners are drawn in
+// their original size, the edges are resized along their length and the
+// center is resized in both directions.
+type NinePatch struct {
+	Image Image
+	// Insets define the size of the fixed borders in source pixels.
+	Insets Insets
+	// Edges and Center define how the edges and the center are resized.
+	Edges, Center PatchMode
+	// BorderScale scales the borders and tiles when drawing, e.g. to adjust a
+	// frame to the renderer's Unit. 0 is interpreted as 1.
+	BorderScale float32
+}
+
+// Borders returns the size of the borders when drawing the patch to the given
+// area. The borders are scaled with BorderScale, and shrunk proportionally
+// if they do not fit into the area.
+func (np NinePatch) Borders(area Rectangle) Insets {
+	scale := np.BorderScale
+	if scale == 0 {
+		scale = 1
+	}
+	ret := Insets{Top: int32(float32(np.Insets.Top)*scale + 0.5),
+		Right:  int32(float32(np.Insets.Right)*scale + 0.5),
+		Bottom: int32(float32(np.Insets.Bottom)*scale + 0.5),
+		Left:   int32(float32(np.Insets.Left)*scale + 0.5)}
+	if sum := ret.Left + ret.Right; sum > area.Width && sum > 0 {
+		ret.Left = int32(int64(ret.Left) * int64(max32(area.Width, 0)) / int64(sum))
+		ret.Right = max32(area.Width, 0) - ret.Left
+	}
+	if sum := ret.Top + ret.Bottom; sum > area.Height && sum > 0 {
+		ret.Top = int32(int64(ret.Top) * int64(max32(area.Height, 0)) / int64(sum))
+		ret.Bottom = max32(area.Height, 0) - ret.Top
+	}
+	return ret
+}
+
+// Draw draws the patch to the given area. Use Borders to calculate the
+// remaining inner area.
+func (np NinePatch) Draw(r Renderer, area Rectangle, alpha uint8) {
+	r.DrawNinePatch(np, area, alpha)
+}
@@ -0,0 +1,111 @@
+package recording
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the name of the environment variable that, when set to a
+// non-empty value, makes AssertGolden write the actual trace to the golden
+// file instead of comparing against it.
+const UpdateGoldenEnv = "QS_UPDATE_GOLDEN"
+
+// AssertGolden compares the textual representation of the given trace with
+// the content of the golden file at path. If they differ, the test fails with
+// a line diff.
+//
+// If the environment variable named by UpdateGoldenEnv is set, the golden
+// file is (over)written with the given trace instead.
+func AssertGolden(tb testing.TB, path string, trace Trace) {
+	tb.Helper()
+	actual := trace.String()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			tb.Fatalf("unable to write golden file: %s", err.Error())
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("unable to read golden file (set %s=1 to create it): %s",
+			UpdateGoldenEnv, err.Error())
+		return
+	}
+	if diff := Diff(string(expected), actual); diff != "" {
+		tb.Errorf("trace differs from %s (-expected +actual):\n%s", path, diff)
+	}
+}
+
+// diffContext is the number of unchanged lines shown around changed lines.
+const diffContext = 2
+
+// Diff returns a line diff of the two given texts, with removed lines
+// prefixed by `-` and added lines prefixed by `+`. Unchanged lines are only
+// shown near changes. Returns the empty string if both texts are equal.
+func Diff(expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+	a := strings.Split(strings.TrimSuffix(expected, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(actual, "\n"), "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var lines []string
+	var changed []bool
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			changed = append(changed, false)
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, "+ "+b[j])
+			changed = append(changed, true)
+			j++
+		default:
+			lines = append(lines, "- "+a[i])
+			changed = append(changed, true)
+			i++
+		}
+	}
+	var out strings.Builder
+	skipped := false
+	for k, line := range lines {
+		near := false
+		for d := k - diffContext; d <= k+diffContext; d++ {
+			if d >= 0 && d < len(changed) && changed[d] {
+				near = true
+				break
+			}
+		}
+		if !near {
+			if !skipped {
+				out.WriteString("  ...\n")
+				skipped = true
+			}
+			continue
+		}
+		skipped = false
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
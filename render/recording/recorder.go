@@ -0,0 +1,418 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+// Recorder is a render.Renderer that records each drawing call into a Trace
+// and forwards it to a backend renderer, e.g. a software.Renderer.
+//
+// Each image created via the Recorder is given a label that identifies it in
+// the trace. Labels consist of the image's origin and a sequence number, e.g.
+// `text#1`, `file#2` or `canvas#3`, and are stable between runs as long as
+// the sequence of calls does not change.
+type Recorder struct {
+	backend render.Renderer
+	trace   Trace
+	labels  map[uint32]string
+	counter int
+	depth   int
+}
+
+// New creates a Recorder that forwards all calls to the given backend.
+func New(backend render.Renderer) *Recorder {
+	return &Recorder{backend: backend, labels: make(map[uint32]string)}
+}
+
+// Trace returns the calls recorded since creation or the last call to Reset.
+func (r *Recorder) Trace() Trace {
+	return r.trace
+}
+
+// Reset discards all recorded calls. Image labels stay valid.
+func (r *Recorder) Reset() {
+	r.trace = nil
+}
+
+// Label returns the label of the given image, or `empty` if it is empty.
+// Images unknown to the Recorder are labelled `unknown`.
+func (r *Recorder) Label(i render.Image) string {
+	if i.IsEmpty() {
+		return "empty"
+	}
+	if label, ok := r.labels[i.TextureID]; ok {
+		return label
+	}
+	return "unknown"
+}
+
+func (r *Recorder) record(c Call) {
+	c.Depth = r.depth
+	r.trace = append(r.trace, c)
+}
+
+func (r *Recorder) register(i render.Image, origin string) string {
+	if i.IsEmpty() {
+		return "empty"
+	}
+	r.counter++
+	label := fmt.Sprintf("%s#%d", origin, r.counter)
+	r.labels[i.TextureID] = label
+	return label
+}
+
+// OutputSize forwards to the backend.
+func (r *Recorder) OutputSize() render.Rectangle {
+	return r.backend.OutputSize()
+}
+
+// Unit forwards to the backend.
+func (r *Recorder) Unit() int32 {
+	return r.backend.Unit()
+}
+
+// FillRect records the call and forwards it to the backend.
+func (r *Recorder) FillRect(t render.Transform, color api.RGBA) {
+	r.record(Call{Op: "FillRect", Transform: &t, Color: &color})
+	r.backend.FillRect(t, color)
+}
+
+// FillGradient records the call and forwards it to the backend.
+func (r *Recorder) FillGradient(t render.Transform, gradient api.Gradient) {
+	r.record(Call{Op: "FillGradient", Transform: &t, Gradient: &gradient})
+	r.backend.FillGradient(t, gradient)
+}
+
+// FillRoundedRect records the call and forwards it to the backend.
+func (r *Recorder) FillRoundedRect(t render.Transform, radius float32,
+	color api.RGBA) {
+	r.record(Call{Op: "FillRoundedRect", Transform: &t, Radius: radius,
+		Color: &color})
+	r.backend.FillRoundedRect(t, radius, color)
+}
+
+// FillEllipse records the call and forwards it to the backend.
+func (r *Recorder) FillEllipse(t render.Transform, color api.RGBA) {
+	r.record(Call{Op: "FillEllipse", Transform: &t, Color: &color})
+	r.backend.FillEllipse(t, color)
+}
+
+// StrokeEllipse records the call and forwards it to the backend.
+func (r *Recorder) StrokeEllipse(t render.Transform, width float32,
+	color api.RGBA) {
+	r.record(Call{Op: "StrokeEllipse", Transform: &t, LineWidth: width,
+		Color: &color})
+	r.backend.StrokeEllipse(t, width, color)
+}
+
+// DrawLine records the call and forwards it to the backend.
+func (r *Recorder) DrawLine(t render.Transform, from, to render.Point,
+	width float32, color api.RGBA) {
+	r.record(Call{Op: "DrawLine", Transform: &t,
+		Points: []render.Point{from, to}, LineWidth: width, Color: &color})
+	r.backend.DrawLine(t, from, to, width, color)
+}
+
+// FillPolygon records the call and forwards it to the backend.
+func (r *Recorder) FillPolygon(t render.Transform, points []render.Point,
+	color api.RGBA) {
+	r.record(Call{Op: "FillPolygon", Transform: &t,
+		Points: append([]render.Point(nil), points...), Color: &color})
+	r.backend.FillPolygon(t, points, color)
+}
+
+// DrawImage records the call and forwards it to the backend.
+func (r *Recorder) DrawImage(image render.Image, t render.Transform,
+	alpha uint8) {
+	r.record(Call{Op: "DrawImage", Image: r.Label(image), Transform: &t,
+		Alpha: &alpha})
+	r.backend.DrawImage(image, t, alpha)
+}
+
+// DrawImageWith records the call and forwards it to the backend.
+func (r *Recorder) DrawImageWith(image render.Image, t render.Transform,
+	alpha uint8, opts render.DrawOptions) {
+	r.record(Call{Op: "DrawImageWith", Image: r.Label(image), Transform: &t,
+		Alpha: &alpha, DrawOptions: &opts})
+	r.backend.DrawImageWith(image, t, alpha, opts)
+}
+
+// DrawImageRegion records the call and forwards it to the backend.
+func (r *Recorder) DrawImageRegion(image render.Image, region render.Rectangle,
+	t render.Transform, alpha uint8) {
+	r.record(Call{Op: "DrawImageRegion", Image: r.Label(image), Region: &region,
+		Transform: &t, Alpha: &alpha})
+	r.backend.DrawImageRegion(image, region, t, alpha)
+}
+
+// DrawNinePatch records the call and forwards it to the backend.
+func (r *Recorder) DrawNinePatch(patch render.NinePatch, area render.Rectangle,
+	alpha uint8) {
+	r.record(Call{Op: "DrawNinePatch", Image: r.Label(patch.Image),
+		Patch: &Patch{Insets: patch.Insets, Edges: patch.Edges,
+			Center: patch.Center, BorderScale: patch.BorderScale},
+		Dest: &area, Alpha: &alpha})
+	r.backend.DrawNinePatch(patch, area, alpha)
+}
+
+// DrawImageMasked records the call and forwards it to the backend.
+func (r *Recorder) DrawImageMasked(image, mask render.Image,
+	t render.Transform, alpha uint8) {
+	r.record(Call{Op: "DrawImageMasked", Image: r.Label(image),
+		Mask: r.Label(mask), Transform: &t, Alpha: &alpha})
+	r.backend.DrawImageMasked(image, mask, t, alpha)
+}
+
+// PushClip records the call and forwards it to the backend.
+func (r *Recorder) PushClip(area render.Rectangle) {
+	r.record(Call{Op: "PushClip", Dest: &area})
+	r.backend.PushClip(area)
+}
+
+// PopClip records the call and forwards it to the backend.
+func (r *Recorder) PopClip() {
+	r.record(Call{Op: "PopClip"})
+	r.backend.PopClip()
+}
+
+// RenderText records the call and forwards it to the backend.
+func (r *Recorder) RenderText(text string, font api.Font) render.Image {
+	ret := r.backend.RenderText(text, font)
+	r.record(Call{Op: "RenderText", Text: text, Font: &font,
+		Result: r.register(ret, "text"), Width: ret.Width, Height: ret.Height})
+	return ret
+}
+
+// RenderRichText records the call and forwards it to the backend.
+func (r *Recorder) RenderRichText(spans []render.TextSpan) render.Image {
+	ret := r.backend.RenderRichText(spans)
+	r.record(Call{Op: "RenderRichText", Spans: append([]render.TextSpan(nil),
+		spans...), Result: r.register(ret, "text"), Width: ret.Width,
+		Height: ret.Height})
+	return ret
+}
+
+// MeasureText forwards to the backend.
+func (r *Recorder) MeasureText(text string, font api.Font) render.TextMetrics {
+	return r.backend.MeasureText(text, font)
+}
+
+// RenderTextBlock records the call and forwards it to the backend.
+func (r *Recorder) RenderTextBlock(text string, font api.Font, maxWidth int32,
+	align render.HAlign, lineSpacing float32) render.TextBlock {
+	ret := r.backend.RenderTextBlock(text, font, maxWidth, align, lineSpacing)
+	r.record(Call{Op: "RenderTextBlock", Text: text, Font: &font,
+		MaxWidth: maxWidth, Align: align, LineSpacing: lineSpacing,
+		Lines: ret.Lines, Result: r.register(ret.Image, "text"),
+		Width: ret.Image.Width, Height: ret.Image.Height})
+	return ret
+}
+
+type canvas struct {
+	r      *Recorder
+	inner  render.Canvas
+	closed bool
+}
+
+func (c *canvas) Finish() render.Image {
+	ret := c.inner.Finish()
+	if !c.closed {
+		c.closed = true
+		c.r.depth--
+		c.r.record(Call{Op: "FinishCanvas", Result: c.r.register(ret, "canvas"),
+			Width: ret.Width, Height: ret.Height})
+	}
+	return ret
+}
+
+func (c *canvas) Close() {
+	c.inner.Close()
+	if !c.closed {
+		c.closed = true
+		c.r.depth--
+		c.r.record(Call{Op: "CloseCanvas"})
+	}
+}
+
+// CreateCanvas records the call and forwards it to the backend. All calls
+// until the canvas is finished or closed are recorded with increased depth.
+func (r *Recorder) CreateCanvas(innerWidth, innerHeight int32,
+	bg api.Background, borders render.Directions) (render.Canvas,
+	render.Rectangle) {
+	inner, content := r.backend.CreateCanvas(innerWidth, innerHeight, bg,
+		borders)
+	r.record(Call{Op: "CreateCanvas", Width: innerWidth, Height: innerHeight,
+		Background: &bg, Borders: borders, Content: &content})
+	r.depth++
+	return &canvas{r: r, inner: inner}, content
+}
+
+// CreateFramedCanvas records the call and forwards it to the backend. All
+// calls until the canvas is finished or closed are recorded with increased
+// depth.
+func (r *Recorder) CreateFramedCanvas(innerWidth, innerHeight int32,
+	bg api.Background, borders render.Borders) (render.Canvas,
+	render.Rectangle) {
+	inner, content := r.backend.CreateFramedCanvas(innerWidth, innerHeight, bg,
+		borders)
+	r.record(Call{Op: "CreateFramedCanvas", Width: innerWidth,
+		Height: innerHeight, Background: &bg, Frame: &borders, Content: &content})
+	r.depth++
+	return &canvas{r: r, inner: inner}, content
+}
+
+// LoadImageFile records the call and forwards it to the backend.
+func (r *Recorder) LoadImageFile(path *url.URL,
+	scaleDownToOutput bool) (render.Image, error) {
+	ret, err := r.backend.LoadImageFile(path, scaleDownToOutput)
+	c := Call{Op: "LoadImageFile", URL: path.String(),
+		ScaleDown: scaleDownToOutput, Width: ret.Width, Height: ret.Height}
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		c.Result = r.register(ret, "file")
+	}
+	r.record(c)
+	return ret, err
+}
+
+// LoadImageMem records the call and forwards it to the backend.
+func (r *Recorder) LoadImageMem(data []byte,
+	scaleDownToOutput bool) (render.Image, error) {
+	ret, err := r.backend.LoadImageMem(data, scaleDownToOutput)
+	c := Call{Op: "LoadImageMem", ScaleDown: scaleDownToOutput,
+		Width: ret.Width, Height: ret.Height}
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		c.Result = r.register(ret, "mem")
+	}
+	r.record(c)
+	return ret, err
+}
+
+// DecodeImageFile forwards to the backend. The call is not recorded since it
+// may be issued from any goroutine; the subsequent UploadImage call is.
+func (r *Recorder) DecodeImageFile(ctx context.Context, path *url.URL,
+	opts render.ImageOptions) (render.DecodedImage, error) {
+	return r.backend.DecodeImageFile(ctx, path, opts)
+}
+
+// UploadImage records the call and forwards it to the backend.
+func (r *Recorder) UploadImage(decoded render.DecodedImage) render.Image {
+	ret := r.backend.UploadImage(decoded)
+	r.record(Call{Op: "UploadImage", Result: r.register(ret, "upload"),
+		Width: ret.Width, Height: ret.Height})
+	return ret
+}
+
+func (r *Recorder) recordAnimation(c Call, ret render.AnimatedImage,
+	err error) {
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		labels := make([]string, len(ret.Frames))
+		for i := range ret.Frames {
+			labels[i] = r.register(ret.Frames[i].Image, "anim")
+		}
+		c.Result = strings.Join(labels, ",")
+		c.Width, c.Height = ret.Width(), ret.Height()
+		c.Frames = len(ret.Frames)
+	}
+	r.record(c)
+}
+
+// LoadAnimatedImageFile records the call and forwards it to the backend.
+func (r *Recorder) LoadAnimatedImageFile(path *url.URL,
+	scaleDownToOutput bool) (render.AnimatedImage, error) {
+	ret, err := r.backend.LoadAnimatedImageFile(path, scaleDownToOutput)
+	r.recordAnimation(Call{Op: "LoadAnimatedImageFile", URL: path.String(),
+		ScaleDown: scaleDownToOutput}, ret, err)
+	return ret, err
+}
+
+// LoadAnimatedImageMem records the call and forwards it to the backend.
+func (r *Recorder) LoadAnimatedImageMem(data []byte,
+	scaleDownToOutput bool) (render.AnimatedImage, error) {
+	ret, err := r.backend.LoadAnimatedImageMem(data, scaleDownToOutput)
+	r.recordAnimation(Call{Op: "LoadAnimatedImageMem",
+		ScaleDown: scaleDownToOutput}, ret, err)
+	return ret, err
+}
+
+// LoadImageFileWith records the call and forwards it to the backend.
+func (r *Recorder) LoadImageFileWith(path *url.URL,
+	opts render.ImageOptions) (render.Image, error) {
+	ret, err := r.backend.LoadImageFileWith(path, opts)
+	c := Call{Op: "LoadImageFileWith", URL: path.String(), Options: &opts,
+		Width: ret.Width, Height: ret.Height}
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		c.Result = r.register(ret, "file")
+	}
+	r.record(c)
+	return ret, err
+}
+
+// LoadImageMemWith records the call and forwards it to the backend.
+func (r *Recorder) LoadImageMemWith(data []byte,
+	opts render.ImageOptions) (render.Image, error) {
+	ret, err := r.backend.LoadImageMemWith(data, opts)
+	c := Call{Op: "LoadImageMemWith", Options: &opts, Width: ret.Width,
+		Height: ret.Height}
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		c.Result = r.register(ret, "mem")
+	}
+	r.record(c)
+	return ret, err
+}
+
+// LoadSVGFile records the call and forwards it to the backend.
+func (r *Recorder) LoadSVGFile(path *url.URL,
+	opts render.ImageOptions) (render.Image, error) {
+	ret, err := r.backend.LoadSVGFile(path, opts)
+	c := Call{Op: "LoadSVGFile", URL: path.String(), Options: &opts,
+		Width: ret.Width, Height: ret.Height}
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		c.Result = r.register(ret, "svg")
+	}
+	r.record(c)
+	return ret, err
+}
+
+// LoadSVGMem records the call and forwards it to the backend.
+func (r *Recorder) LoadSVGMem(data []byte,
+	opts render.ImageOptions) (render.Image, error) {
+	ret, err := r.backend.LoadSVGMem(data, opts)
+	c := Call{Op: "LoadSVGMem", Options: &opts, Width: ret.Width,
+		Height: ret.Height}
+	if err != nil {
+		c.Error = err.Error()
+	} else {
+		c.Result = r.register(ret, "svg")
+	}
+	r.record(c)
+	return ret, err
+}
+
+// FreeImage records the call and forwards it to the backend.
+func (r *Recorder) FreeImage(i *render.Image) {
+	if !i.IsEmpty() {
+		r.record(Call{Op: "FreeImage", Image: r.Label(*i)})
+		delete(r.labels, i.TextureID)
+	}
+	r.backend.FreeImage(i)
+}
+
+var _ render.Renderer = (*Recorder)(nil)
@@ -0,0 +1,169 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+	"github.com/QuestScreen/api/render/software"
+)
+
+// record runs a fixed sequence of calls and returns the recorded trace. If
+// alpha differs, the trace differs in a single line.
+func record(alpha uint8) Trace {
+	r := New(software.New(software.Options{Width: 8, Height: 8}))
+	r.FillRect(render.Rectangle{X: 1, Y: 2, Width: 3, Height: 4}.Transformation(),
+		api.RGBA{R: 255, A: 255})
+	img := r.UploadImage(render.DecodedImage{
+		Pixels: image.NewRGBA(image.Rect(0, 0, 2, 2))})
+	c, _ := r.CreateCanvas(4, 4, api.RGB{R: 1}.AsBackground(), 0)
+	r.DrawImage(img, render.Rectangle{Width: 4, Height: 4}.Transformation(),
+		alpha)
+	patch := render.NinePatch{Image: c.Finish(), Insets: render.UniformInsets(1)}
+	r.DrawNinePatch(patch, render.Rectangle{Width: 8, Height: 8}, 255)
+	return r.Trace()
+}
+
+const expectedTrace = `FillRect rect=(1,2 3x4) color=#ff0000ff
+UploadImage -> upload#1 2x2
+CreateCanvas 4x4 bg=(#010000ff #00000000 texture=-1) borders=- content=(0,0 4x4)
+  DrawImage upload#1 rect=(0,0 4x4) alpha=128
+FinishCanvas -> canvas#2 4x4
+DrawNinePatch canvas#2 rect=(0,0 8x8) insets=1,1,1,1 edges=Stretch center=Stretch alpha=255
+`
+
+func TestTrace(t *testing.T) {
+	trace := record(128)
+	if s := trace.String(); s != expectedTrace {
+		t.Errorf("unexpected trace (-expected +actual):\n%s",
+			Diff(expectedTrace, s))
+	}
+	if !trace.DrawnInto("upload#1", render.Rectangle{Width: 4, Height: 4}, 128) {
+		t.Error("DrawnInto did not find the DrawImage call")
+	}
+	if !trace.DrawnInto("canvas#2", render.Rectangle{Width: 8, Height: 8}, 255) {
+		t.Error("DrawnInto did not find the DrawNinePatch call")
+	}
+	if trace.DrawnInto("upload#1", render.Rectangle{Width: 4, Height: 4}, 255) {
+		t.Error("DrawnInto ignored alpha")
+	}
+}
+
+func TestTraceJSON(t *testing.T) {
+	data, err := json.Marshal(record(128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "TextureID") {
+		t.Errorf("JSON trace contains texture IDs: %s", data)
+	}
+	var loaded Trace
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if s := loaded.String(); s != expectedTrace {
+		t.Errorf("loaded trace differs (-expected +actual):\n%s",
+			Diff(expectedTrace, s))
+	}
+}
+
+func TestCallWithoutTransform(t *testing.T) {
+	var loaded Trace
+	if err := json.Unmarshal([]byte(
+		`[{"op":"FillRect","color":"#ff0000ff"}]`), &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if s := loaded.String(); s != "FillRect transform=none color=#ff0000ff\n" {
+		t.Errorf("unexpected trace: %q", s)
+	}
+	if _, ok := loaded[0].Area(); ok {
+		t.Error("Area of call without transform returned ok")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name, expected, actual, diff string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"changed line", "a\nb\nc\n", "a\nx\nc\n", "  a\n- b\n+ x\n  c\n"},
+		{"added line", "a\n", "a\nb\n", "  a\n+ b\n"},
+		{"removed line", "a\nb\n", "b\n", "- a\n  b\n"},
+		{"context", "1\n2\n3\n4\n5\n6\n7\n", "1\n2\n3\n4\n5\n6\nx\n",
+			"  ...\n  5\n  6\n- 7\n+ x\n"},
+	}
+	for _, tt := range tests {
+		if diff := Diff(tt.expected, tt.actual); diff != tt.diff {
+			t.Errorf("%s: got diff\n%s\nwant\n%s", tt.name, diff, tt.diff)
+		}
+	}
+}
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors, fatals []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...interface{}) {
+	f.fatals = append(f.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trace.golden")
+	os.Unsetenv(UpdateGoldenEnv)
+
+	var missing fakeTB
+	AssertGolden(&missing, path, record(128))
+	if len(missing.fatals) != 1 {
+		t.Errorf("missing golden file: got fatals %v, want one", missing.fatals)
+	}
+
+	os.Setenv(UpdateGoldenEnv, "1")
+	var update fakeTB
+	AssertGolden(&update, path, record(128))
+	os.Unsetenv(UpdateGoldenEnv)
+	if len(update.errors) != 0 || len(update.fatals) != 0 {
+		t.Fatalf("update failed: %v %v", update.errors, update.fatals)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil ||
+		string(data) != expectedTrace {
+		t.Fatalf("golden file not written correctly: %q, %v", data, err)
+	}
+
+	var matching fakeTB
+	AssertGolden(&matching, path, record(128))
+	if len(matching.errors) != 0 || len(matching.fatals) != 0 {
+		t.Errorf("matching trace failed: %v %v", matching.errors, matching.fatals)
+	}
+
+	var mismatching fakeTB
+	AssertGolden(&mismatching, path, record(64))
+	if len(mismatching.errors) != 1 {
+		t.Fatalf("mismatching trace: got errors %v, want one",
+			mismatching.errors)
+	}
+	if msg := mismatching.errors[0]; !strings.Contains(msg,
+		"-   DrawImage upload#1 rect=(0,0 4x4) alpha=128\n"+
+			"+   DrawImage upload#1 rect=(0,0 4x4) alpha=64\n") {
+		t.Errorf("error does not contain the diff: %s", msg)
+	}
+}
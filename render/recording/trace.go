@@ -0,0 +1,467 @@
+package recording
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+// Call is a recorded call to a render.Renderer.
+// Only the fields relevant to the call's Op are set.
+type Call struct {
+	// Op is the name of the called Renderer method, or FinishCanvas /
+	// CloseCanvas for calls on a Canvas.
+	Op string `json:"op"`
+	// Depth is the number of canvases active while the call was issued.
+	Depth int `json:"depth,omitempty"`
+	// Image is the label of the image the call operates on.
+	Image     string            `json:"image,omitempty"`
+	Transform *render.Transform `json:"transform,omitempty"`
+	Color     *api.RGBA         `json:"color,omitempty"`
+	Alpha     *uint8            `json:"alpha,omitempty"`
+	Text      string            `json:"text,omitempty"`
+	Font      *api.Font         `json:"font,omitempty"`
+	Spans     []render.TextSpan `json:"spans,omitempty"`
+	// Radius, LineWidth and Points describe shape drawing calls.
+	Radius    float32        `json:"radius,omitempty"`
+	LineWidth float32        `json:"lineWidth,omitempty"`
+	Points    []render.Point `json:"points,omitempty"`
+	// Region is the drawn part of the image in a DrawImageRegion call.
+	Region *render.Rectangle `json:"region,omitempty"`
+	// Patch and Dest describe a DrawNinePatch call. Dest is also the area
+	// given to PushClip.
+	Patch *Patch            `json:"patch,omitempty"`
+	Dest  *render.Rectangle `json:"dest,omitempty"`
+	// Mask is the label of the mask image in a DrawImageMasked call.
+	Mask string `json:"mask,omitempty"`
+	// DrawOptions are given to DrawImageWith.
+	DrawOptions *render.DrawOptions `json:"drawOptions,omitempty"`
+	// MaxWidth, Align, LineSpacing and Lines describe a RenderTextBlock call.
+	MaxWidth    int32             `json:"maxWidth,omitempty"`
+	Align       render.HAlign     `json:"align,omitempty"`
+	LineSpacing float32           `json:"lineSpacing,omitempty"`
+	Lines       int               `json:"lines,omitempty"`
+	Background  *api.Background   `json:"background,omitempty"`
+	Borders     render.Directions `json:"borders,omitempty"`
+	Frame       *render.Borders   `json:"frame,omitempty"`
+	Gradient    *api.Gradient     `json:"gradient,omitempty"`
+	Content     *render.Rectangle `json:"content,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	ScaleDown   bool              `json:"scaleDown,omitempty"`
+	// Options are given to LoadImageFileWith, LoadImageMemWith, LoadSVGFile
+	// and LoadSVGMem.
+	Options *render.ImageOptions `json:"options,omitempty"`
+	// Width and Height give the inner size for CreateCanvas and
+	// CreateFramedCanvas, and the size of
+	// the resulting image for calls that create one.
+	Width  int32 `json:"width,omitempty"`
+	Height int32 `json:"height,omitempty"`
+	// Result is the label of the image created by the call. For calls that
+	// load an animated image, it contains the labels of all frames, separated
+	// by commas.
+	Result string `json:"result,omitempty"`
+	// Frames is the number of frames of a loaded animated image.
+	Frames int `json:"frames,omitempty"`
+	// Error is the message of the error returned by the call.
+	Error string `json:"error,omitempty"`
+}
+
+// Patch describes the nine-patch of a DrawNinePatch call. Its image is given
+// by the call's Image label, since texture IDs are not stable between runs.
+type Patch struct {
+	Insets      render.Insets    `json:"insets"`
+	Edges       render.PatchMode `json:"edges"`
+	Center      render.PatchMode `json:"center"`
+	BorderScale float32          `json:"borderScale,omitempty"`
+}
+
+// Area returns the rectangle that the unit square is transformed into by the
+// call's Transform. ok is false if the call has no Transform, or if the
+// Transform contains rotation or does not map to whole pixels.
+func (c Call) Area() (area render.Rectangle, ok bool) {
+	if c.Transform == nil {
+		return
+	}
+	t := *c.Transform
+	if t[1] != 0 || t[2] != 0 || t[0] < 0 || t[3] < 0 {
+		return
+	}
+	values := [4]float32{t[4] - t[0]/2, t[5] - t[3]/2, t[0], t[3]}
+	var ints [4]int32
+	for i, v := range values {
+		ints[i] = int32(v)
+		if float32(ints[i]) != v {
+			return
+		}
+	}
+	return render.Rectangle{X: ints[0], Y: ints[1], Width: ints[2],
+		Height: ints[3]}, true
+}
+
+func formatFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+func formatRect(r render.Rectangle) string {
+	return fmt.Sprintf("(%d,%d %dx%d)", r.X, r.Y, r.Width, r.Height)
+}
+
+func (c Call) geometry() string {
+	if area, ok := c.Area(); ok {
+		return "rect=" + formatRect(area)
+	}
+	if c.Transform == nil {
+		return "transform=none"
+	}
+	parts := make([]string, 6)
+	for i, v := range c.Transform {
+		parts[i] = formatFloat(v)
+	}
+	return "transform=[" + strings.Join(parts, " ") + "]"
+}
+
+var fontSizeNames = [api.NumFontSizes]string{
+	"Small", "Content", "Medium", "Heading", "Large", "Huge"}
+
+var fontStyleNames = [api.NumFontStyles]string{
+	"Regular", "Bold", "Italic", "BoldItalic"}
+
+func formatFont(f *api.Font) string {
+	size, style := strconv.Itoa(int(f.Size)), strconv.Itoa(int(f.Style))
+	if f.Size >= 0 && f.Size < api.NumFontSizes {
+		size = fontSizeNames[f.Size]
+	}
+	if f.Style >= 0 && f.Style < api.NumFontStyles {
+		style = fontStyleNames[f.Style]
+	}
+	var effects strings.Builder
+	if f.Outline.Color.A != 0 {
+		fmt.Fprintf(&effects, " outline=%s/%s", formatFloat(f.Outline.Width),
+			f.Outline.Color.HexRepr())
+	}
+	if f.Shadow.Color.A != 0 {
+		fmt.Fprintf(&effects, " shadow=%s,%s/%s", formatFloat(f.Shadow.OffsetX),
+			formatFloat(f.Shadow.OffsetY), f.Shadow.Color.HexRepr())
+	}
+	if f.Glow.Color.A != 0 {
+		fmt.Fprintf(&effects, " glow=%s/%s", formatFloat(f.Glow.Radius),
+			f.Glow.Color.HexRepr())
+	}
+	return fmt.Sprintf("(%d %s %s %s%s)", f.FamilyIndex, size, style,
+		f.Color.HexRepr(), effects.String())
+}
+
+func formatPoints(points []render.Point) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("(%s,%s)", formatFloat(p.X), formatFloat(p.Y))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatInsets(i render.Insets) string {
+	return fmt.Sprintf("%d,%d,%d,%d", i.Top, i.Right, i.Bottom, i.Left)
+}
+
+func formatPatchMode(m render.PatchMode) string {
+	switch m {
+	case render.Stretch:
+		return "Stretch"
+	case render.Tile:
+		return "Tile"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
+func formatImageOptions(o *render.ImageOptions) string {
+	var fit string
+	switch o.Fit {
+	case render.Contain:
+		fit = "Contain"
+	case render.Cover:
+		fit = "Cover"
+	case render.Exact:
+		fit = "Exact"
+	default:
+		fit = strconv.Itoa(int(o.Fit))
+	}
+	return fmt.Sprintf("size=%dx%d fit=%s scaleDown=%v", o.Width, o.Height,
+		fit, o.ScaleDownToOutput)
+}
+
+var blendModeNames = [render.NumBlendModes]string{
+	"Normal", "Additive", "Multiply", "Screen"}
+
+// formatDrawOptions lists the options that differ from the zero value, each
+// preceded by a space.
+func formatDrawOptions(o *render.DrawOptions) string {
+	var b strings.Builder
+	if o.Blend != render.Normal {
+		blend := strconv.Itoa(int(o.Blend))
+		if o.Blend >= 0 && o.Blend < render.NumBlendModes {
+			blend = blendModeNames[o.Blend]
+		}
+		b.WriteString(" blend=" + blend)
+	}
+	if o.Tint.A != 0 {
+		b.WriteString(" tint=" + o.Tint.HexRepr())
+	}
+	for _, adjustment := range [...]struct {
+		name  string
+		value float32
+	}{{"grayscale", o.Grayscale}, {"brightness", o.Brightness},
+		{"contrast", o.Contrast}, {"saturation", o.Saturation}} {
+		if adjustment.value != 0 {
+			fmt.Fprintf(&b, " %s=%s", adjustment.name,
+				formatFloat(adjustment.value))
+		}
+	}
+	return b.String()
+}
+
+func formatHAlign(a render.HAlign) string {
+	switch a {
+	case render.Left:
+		return "Left"
+	case render.Center:
+		return "Center"
+	case render.Right:
+		return "Right"
+	case render.HStretch:
+		return "HStretch"
+	default:
+		return strconv.Itoa(int(a))
+	}
+}
+
+func formatGradient(g *api.Gradient) string {
+	var b strings.Builder
+	switch g.Kind {
+	case api.NoGradient:
+		return "none"
+	case api.LinearGradient:
+		fmt.Fprintf(&b, "linear(%s", formatFloat(g.Angle))
+	case api.RadialGradient:
+		b.WriteString("radial(")
+	default:
+		fmt.Fprintf(&b, "kind%d(", int(g.Kind))
+	}
+	for i, stop := range g.UsedStops() {
+		if i > 0 || g.Kind == api.LinearGradient {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%s@%s", stop.Color.HexRepr(), formatFloat(stop.Offset))
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func formatBackground(bg *api.Background) string {
+	if bg.Gradient.Active() {
+		return fmt.Sprintf("(%s %s texture=%d)", formatGradient(&bg.Gradient),
+			bg.Secondary.HexRepr(), bg.TextureIndex)
+	}
+	return fmt.Sprintf("(%s %s texture=%d)", bg.Primary.HexRepr(),
+		bg.Secondary.HexRepr(), bg.TextureIndex)
+}
+
+func formatDirections(d render.Directions) string {
+	var b strings.Builder
+	for i, dir := range [4]render.Directions{
+		render.North, render.East, render.South, render.West} {
+		if d&dir != 0 {
+			b.WriteByte("NESW"[i])
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+func formatBorders(b *render.Borders) string {
+	parts := make([]string, 0, 4)
+	for i, border := range [4]render.Border{b.Top, b.Right, b.Bottom, b.Left} {
+		if border.Width > 0 {
+			parts = append(parts, fmt.Sprintf("%c%d%s", "NESW"[i], border.Width,
+				border.Color.HexRepr()))
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ",")
+}
+
+func (c Call) result() string {
+	if c.Error != "" {
+		return " -> error: " + c.Error
+	}
+	return fmt.Sprintf(" -> %s %dx%d", c.Result, c.Width, c.Height)
+}
+
+// String returns a human-readable single-line representation of the call.
+func (c Call) String() string {
+	var s string
+	switch c.Op {
+	case "FillRect":
+		s = fmt.Sprintf("FillRect %s color=%s", c.geometry(), c.Color.HexRepr())
+	case "FillGradient":
+		s = fmt.Sprintf("FillGradient %s gradient=%s", c.geometry(),
+			formatGradient(c.Gradient))
+	case "FillRoundedRect":
+		s = fmt.Sprintf("FillRoundedRect %s radius=%s color=%s", c.geometry(),
+			formatFloat(c.Radius), c.Color.HexRepr())
+	case "FillEllipse":
+		s = fmt.Sprintf("FillEllipse %s color=%s", c.geometry(),
+			c.Color.HexRepr())
+	case "StrokeEllipse":
+		s = fmt.Sprintf("StrokeEllipse %s width=%s color=%s", c.geometry(),
+			formatFloat(c.LineWidth), c.Color.HexRepr())
+	case "DrawLine":
+		s = fmt.Sprintf("DrawLine %s %s width=%s color=%s", c.geometry(),
+			formatPoints(c.Points), formatFloat(c.LineWidth), c.Color.HexRepr())
+	case "FillPolygon":
+		s = fmt.Sprintf("FillPolygon %s %s color=%s", c.geometry(),
+			formatPoints(c.Points), c.Color.HexRepr())
+	case "DrawImage":
+		s = fmt.Sprintf("DrawImage %s %s alpha=%d", c.Image, c.geometry(),
+			*c.Alpha)
+	case "DrawImageWith":
+		s = fmt.Sprintf("DrawImageWith %s %s alpha=%d%s", c.Image, c.geometry(),
+			*c.Alpha, formatDrawOptions(c.DrawOptions))
+	case "DrawImageRegion":
+		s = fmt.Sprintf("DrawImageRegion %s region=%s %s alpha=%d", c.Image,
+			formatRect(*c.Region), c.geometry(), *c.Alpha)
+	case "DrawNinePatch":
+		s = fmt.Sprintf("DrawNinePatch %s rect=%s insets=%s edges=%s center=%s",
+			c.Image, formatRect(*c.Dest), formatInsets(c.Patch.Insets),
+			formatPatchMode(c.Patch.Edges), formatPatchMode(c.Patch.Center))
+		if c.Patch.BorderScale != 0 {
+			s += " scale=" + formatFloat(c.Patch.BorderScale)
+		}
+		s += fmt.Sprintf(" alpha=%d", *c.Alpha)
+	case "DrawImageMasked":
+		s = fmt.Sprintf("DrawImageMasked %s mask=%s %s alpha=%d", c.Image,
+			c.Mask, c.geometry(), *c.Alpha)
+	case "PushClip":
+		s = "PushClip rect=" + formatRect(*c.Dest)
+	case "PopClip":
+		s = "PopClip"
+	case "RenderText":
+		s = fmt.Sprintf("RenderText %q font=%s", c.Text, formatFont(c.Font)) +
+			c.result()
+	case "RenderRichText":
+		parts := make([]string, len(c.Spans))
+		for i := range c.Spans {
+			parts[i] = fmt.Sprintf("%q%s", c.Spans[i].Text,
+				formatFont(&c.Spans[i].Font))
+		}
+		s = "RenderRichText " + strings.Join(parts, " ") + c.result()
+	case "RenderTextBlock":
+		s = fmt.Sprintf("RenderTextBlock %q font=%s maxWidth=%d align=%s spacing=%s",
+			c.Text, formatFont(c.Font), c.MaxWidth, formatHAlign(c.Align),
+			formatFloat(c.LineSpacing)) + c.result() +
+			fmt.Sprintf(" lines=%d", c.Lines)
+	case "CreateCanvas":
+		s = fmt.Sprintf("CreateCanvas %dx%d bg=%s borders=%s content=%s",
+			c.Width, c.Height, formatBackground(c.Background),
+			formatDirections(c.Borders), formatRect(*c.Content))
+	case "CreateFramedCanvas":
+		s = fmt.Sprintf("CreateFramedCanvas %dx%d bg=%s borders=%s content=%s",
+			c.Width, c.Height, formatBackground(c.Background),
+			formatBorders(c.Frame), formatRect(*c.Content))
+	case "FinishCanvas":
+		s = "FinishCanvas" + c.result()
+	case "LoadImageFile":
+		s = fmt.Sprintf("LoadImageFile %s scaleDown=%v", c.URL, c.ScaleDown) +
+			c.result()
+	case "LoadImageMem":
+		s = fmt.Sprintf("LoadImageMem scaleDown=%v", c.ScaleDown) + c.result()
+	case "LoadImageFileWith":
+		s = fmt.Sprintf("LoadImageFileWith %s %s", c.URL,
+			formatImageOptions(c.Options)) + c.result()
+	case "LoadImageMemWith":
+		s = "LoadImageMemWith " + formatImageOptions(c.Options) + c.result()
+	case "LoadSVGFile":
+		s = fmt.Sprintf("LoadSVGFile %s %s", c.URL,
+			formatImageOptions(c.Options)) + c.result()
+	case "LoadSVGMem":
+		s = "LoadSVGMem " + formatImageOptions(c.Options) + c.result()
+	case "UploadImage":
+		s = "UploadImage" + c.result()
+	case "LoadAnimatedImageFile":
+		s = fmt.Sprintf("LoadAnimatedImageFile %s scaleDown=%v frames=%d", c.URL,
+			c.ScaleDown, c.Frames) + c.result()
+	case "LoadAnimatedImageMem":
+		s = fmt.Sprintf("LoadAnimatedImageMem scaleDown=%v frames=%d", c.ScaleDown,
+			c.Frames) + c.result()
+	case "FreeImage":
+		s = "FreeImage " + c.Image
+	default:
+		s = c.Op
+	}
+	return strings.Repeat("  ", c.Depth) + s
+}
+
+// Trace is a sequence of recorded calls.
+type Trace []Call
+
+// String returns the trace with one call per line, indented by depth.
+func (t Trace) String() string {
+	var b strings.Builder
+	for _, c := range t {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Ops returns all calls with the given Op.
+func (t Trace) Ops(op string) Trace {
+	var ret Trace
+	for _, c := range t {
+		if c.Op == op {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Draws returns all calls that drew the image with the given label, i.e.
+// DrawImage, DrawImageWith, DrawImageRegion, DrawImageMasked and
+// DrawNinePatch calls. Calls using the image only as mask are not included.
+func (t Trace) Draws(label string) Trace {
+	var ret Trace
+	for _, c := range t {
+		switch c.Op {
+		case "DrawImage", "DrawImageWith", "DrawImageRegion", "DrawImageMasked",
+			"DrawNinePatch":
+			if c.Image == label {
+				ret = append(ret, c)
+			}
+		}
+	}
+	return ret
+}
+
+// DrawnInto tests whether the image with the given label has been drawn into
+// the given area with the given alpha value. For DrawNinePatch calls, the
+// area is the call's destination; for all other calls, it is given by Area.
+func (t Trace) DrawnInto(label string, area render.Rectangle,
+	alpha uint8) bool {
+	for _, c := range t.Draws(label) {
+		a, ok := c.Area()
+		if c.Dest != nil {
+			a, ok = *c.Dest, true
+		}
+		if ok && a == area && c.Alpha != nil && *c.Alpha == alpha {
+			return true
+		}
+	}
+	return false
+}
@@ -142,3 +142,8 @@ func (r Rectangle) Carve(edge Directions,
 func (r Rectangle) Fill(renderer Renderer, color api.RGBA) {
 	renderer.FillRect(r.Transformation(), color)
 }
+
+// FillGradient fills the rectangle with the given gradient.
+func (r Rectangle) FillGradient(renderer Renderer, gradient api.Gradient) {
+	renderer.FillGradient(r.Transformation(), gradient)
+}
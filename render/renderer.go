@@ -1,6 +1,7 @@
 package render
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/QuestScreen/api"
@@ -44,6 +45,21 @@ func (i Image) Draw(r Renderer, area Rectangle, alpha uint8) {
 	r.DrawImage(i, area.Transformation(), alpha)
 }
 
+// DrawRegion draws the given region of the image to the given rectangular
+// area. The region is given in pixels of the image, with (0, 0) being its
+// lower left corner. It will be stretched to fit the whole area.
+func (i Image) DrawRegion(r Renderer, region, area Rectangle, alpha uint8) {
+	r.DrawImageRegion(i, region, area.Transformation(), alpha)
+}
+
+// DrawMasked draws the image to the given rectangular area like Draw, but
+// only where the given mask is opaque. The mask is stretched to the area as
+// well.
+func (i Image) DrawMasked(r Renderer, mask Image, area Rectangle,
+	alpha uint8) {
+	r.DrawImageMasked(i, mask, area.Transformation(), alpha)
+}
+
 // Renderer describes an object providing functions for rendering objects.
 type Renderer interface {
 	// OutputSize returns a rectangle that describes the dimensions in pixels
@@ -68,31 +84,146 @@ type Renderer interface {
 	//
 	// For the high-level API, use Rectangle's Fill() instead.
 	FillRect(t Transform, color api.RGBA)
+	// FillGradient fills a rectangle with the given gradient. The rectangle is
+	// a square with edge length of 1.0 centered around the origin, transformed
+	// with the given transformation. The gradient's angle is relative to the
+	// transformed square. Does nothing if the gradient is not active.
+	//
+	// For the high-level API, use Rectangle's FillGradient() instead.
+	FillGradient(t Transform, gradient api.Gradient)
+	// FillRoundedRect works like FillRect, but rounds the corners of the
+	// rectangle with the given radius. The radius is given in pixels and
+	// limited to half of the rectangle's shorter side.
+	//
+	// For the high-level API, use Rectangle's FillRounded() instead.
+	FillRoundedRect(t Transform, radius float32, color api.RGBA)
+	// FillEllipse fills the ellipse inscribed into a square with edge length
+	// of 1.0 centered around the origin, transformed with the given
+	// transformation.
+	//
+	// For the high-level API, use Rectangle's FillEllipse() instead.
+	FillEllipse(t Transform, color api.RGBA)
+	// StrokeEllipse draws the outline of the ellipse FillEllipse would fill.
+	// The outline lies inside of the ellipse and has the given width in pixels.
+	//
+	// For the high-level API, use Rectangle's StrokeEllipse() instead.
+	StrokeEllipse(t Transform, width float32, color api.RGBA)
+	// DrawLine draws a line segment between the given points, which are
+	// transformed with the given transformation. The line has the given width
+	// in pixels and ends exactly at the points.
+	DrawLine(t Transform, from, to Point, width float32, color api.RGBA)
+	// FillPolygon fills the convex polygon with the given corners, which are
+	// transformed with the given transformation. The result is undefined for
+	// polygons that are not convex.
+	FillPolygon(t Transform, points []Point, color api.RGBA)
 	// DrawImage renders the given image if it is not empty on a square with
 	// edge length of 1.0 centered around the origin, transformed with the given
 	// transformation. alpha modifies the image's opacity.
 	//
 	// For the high-level API, use Image's Draw() instead.
 	DrawImage(image Image, t Transform, alpha uint8)
+	// DrawImageWith is like DrawImage, but applies the given blend mode and
+	// color adjustments.
+	//
+	// For the high-level API, use Image's DrawWith() instead.
+	DrawImageWith(image Image, t Transform, alpha uint8, opts DrawOptions)
+	// DrawImageRegion is like DrawImage, but renders only the given region of
+	// the image. The region is given in pixels of the image, with (0, 0) being
+	// its lower left corner.
+	//
+	// For the high-level API, use Image's DrawRegion() instead.
+	DrawImageRegion(image Image, region Rectangle, t Transform, alpha uint8)
+	// DrawNinePatch draws the given nine-patch to the given area. Borders are
+	// aligned to whole pixels. alpha modifies the image's opacity.
+	//
+	// For the high-level API, use NinePatch's Draw() instead.
+	DrawNinePatch(patch NinePatch, area Rectangle, alpha uint8)
+	// DrawImageMasked is like DrawImage, but multiplies the image's opacity
+	// with the alpha channel of mask, which is stretched over the same area.
+	// Use it to crop content to arbitrary shapes, e.g. by rendering the shape
+	// into a Canvas and using the result as mask. Nothing is drawn if mask is
+	// empty.
+	//
+	// For the high-level API, use Image's DrawMasked() instead.
+	DrawImageMasked(image, mask Image, t Transform, alpha uint8)
+	// PushClip restricts all following drawing operations to the given area,
+	// intersected with the currently active clip area, until the matching
+	// PopClip. The area is given in the coordinates of the current rendering
+	// area, see OutputSize.
+	//
+	// Each Canvas has its own clip areas: a new Canvas is not clipped, and
+	// finishing or closing it restores the clip areas of the previous target.
+	// Clip areas not popped before that are discarded.
+	PushClip(area Rectangle)
+	// PopClip removes the clip area pushed last. Does nothing if the current
+	// rendering area has no clip area.
+	PopClip()
 	// RenderText renders the given text with the given font into an image with
 	// transparent background.
+	//
+	// The font's outline, shadow and glow are drawn behind the text. The image
+	// is extended by the space these effects require, so it may be larger than
+	// the text itself; MeasureText includes this space as well.
 	// Returns an empty image if it wasn't able to create the texture.
 	RenderText(text string, font api.Font) Image
+	// RenderRichText renders the given spans into a single line in an image
+	// with transparent background. Each span is rendered with its own font;
+	// all spans share a common baseline.
+	//
+	// Use ParseMarkup to create spans from a string with inline markup.
+	// Returns an empty image if it wasn't able to create the texture.
+	RenderRichText(spans []TextSpan) Image
+	// MeasureText returns the metrics of the image RenderText would create for
+	// the given text and font, without creating a texture.
+	//
+	// Use this to calculate layouts before rendering text.
+	MeasureText(text string, font api.Font) TextMetrics
+	// RenderTextBlock renders the given text with the given font into an image
+	// with transparent background, breaking it into multiple lines so that no
+	// line is wider than maxWidth.
+	//
+	// Lines are broken at spaces; words that do not fit into a line by
+	// themselves are broken between characters. Each newline character in the
+	// text starts a new line.
+	//
+	// The image is as wide as the widest line, and each line is positioned
+	// according to align. If align is HStretch, the image is maxWidth wide and
+	// each line except for the last line of a paragraph is justified.
+	//
+	// lineSpacing is a factor applied to the font's default distance between
+	// two lines; values <= 0 are treated as 1.0.
+	// Returns a TextBlock with an empty image if text is empty or it wasn't
+	// able to create the texture.
+	RenderTextBlock(text string, font api.Font, maxWidth int32, align HAlign,
+		lineSpacing float32) TextBlock
 	// CreateCanvas creates a canvas to draw content into, and fills it with the
 	// given background. The returned content rectangle is the canvas area minus
 	// the borders.
 	//
 	// Borders are added in each given direction. Border width/height is added to
-	// the given innerWidth / innerHeight values.
+	// the given innerWidth / innerHeight values. Each border is one Unit wide;
+	// use CreateFramedCanvas for other widths and colors.
 	//
 	// The texture created by the canvas will have an alpha channel only if the
 	// primary color has an alpha value other than 255, or if a mask is set and
-	// the secondary color has an alpha value other than 255.
+	// the secondary color has an alpha value other than 255. If the background
+	// has an active gradient, the colors of its stops are considered instead of
+	// the primary color.
 	CreateCanvas(innerWidth, innerHeight int32, bg api.Background,
 		borders Directions) (canvas Canvas, content Rectangle)
+	// CreateFramedCanvas works like CreateCanvas, but draws the given borders
+	// which may have an individual width and color at each edge.
+	//
+	// The border widths are added to the given innerWidth / innerHeight values,
+	// the returned content rectangle is the canvas area minus the borders.
+	CreateFramedCanvas(innerWidth, innerHeight int32, bg api.Background,
+		borders Borders) (canvas Canvas, content Rectangle)
 	// LoadImageFile loads an image file from the specified URL.
 	// if an error is returned, the returned image is empty.
 	//
+	// Images are rotated and flipped according to their EXIF orientation, if
+	// any. This applies to all methods that load images.
+	//
 	// if scaleDownToOutput is true, the image is scaled down to the output
 	// context's dimensions so that it completely fits into the display while
 	// preserving aspect ratio.
@@ -105,6 +236,51 @@ type Renderer interface {
 	//
 	// scaleDownToOutput work like for LoadImageFile.
 	LoadImageMem(data []byte, scaleDownToOutput bool) (Image, error)
+	// LoadImageFileWith loads an image file from the specified URL and scales
+	// it according to the given options. This avoids keeping images in memory
+	// at a larger size than they are displayed.
+	// if an error is returned, the returned image is empty.
+	//
+	// The image will always be scaled down to GL_MAX_TEXTURE_SIZE if its
+	// dimensions exceed it.
+	LoadImageFileWith(path *url.URL, opts ImageOptions) (Image, error)
+	// LoadImageMemWith loads an image from data in memory and scales it
+	// according to the given options, see LoadImageFileWith.
+	LoadImageMemWith(data []byte, opts ImageOptions) (Image, error)
+	// LoadSVGFile loads an SVG file from the specified URL and rasterizes it
+	// at the size given by opts. If neither opts.Width nor opts.Height is set,
+	// the size defined by the document is used. Since the image is rendered
+	// at its final size, it stays sharp at any scale.
+	// See package svg for the supported subset of SVG.
+	// if an error is returned, the returned image is empty.
+	LoadSVGFile(path *url.URL, opts ImageOptions) (Image, error)
+	// LoadSVGMem loads an SVG document from data in memory, see LoadSVGFile.
+	LoadSVGMem(data []byte, opts ImageOptions) (Image, error)
+	// DecodeImageFile loads and decodes an image file from the specified URL
+	// and scales it like LoadImageFileWith does, but does not create a
+	// texture.
+	// Unlike all other methods, it may be called from any goroutine. It stops
+	// early and returns ctx.Err() if ctx is cancelled.
+	//
+	// Use UploadImage on the render thread to create the texture, or
+	// LoadImageAsync for the high-level API.
+	DecodeImageFile(ctx context.Context, path *url.URL,
+		opts ImageOptions) (DecodedImage, error)
+	// UploadImage creates a texture from an image returned by DecodeImageFile.
+	UploadImage(decoded DecodedImage) Image
+	// LoadAnimatedImageFile loads an animated GIF or APNG file from the
+	// specified URL. Each frame is composed into a separate image of the whole
+	// animation's size. Files that are not animated yield a single frame.
+	// if an error is returned, the returned animation is empty.
+	//
+	// scaleDownToOutput works like for LoadImageFile. Use AnimatedImage's
+	// Free() to destroy the frames' textures.
+	LoadAnimatedImageFile(path *url.URL,
+		scaleDownToOutput bool) (AnimatedImage, error)
+	// LoadAnimatedImageMem loads an animated image from data in memory, see
+	// LoadAnimatedImageFile.
+	LoadAnimatedImageMem(data []byte,
+		scaleDownToOutput bool) (AnimatedImage, error)
 	// FreeImage destroys the texture associated with the image (if one exists)
 	// and sets i to be the empty image. Does nothing on empty images.
 	FreeImage(i *Image)
@@ -0,0 +1,28 @@
+package render
+
+import "github.com/QuestScreen/api"
+
+// Point is a point in the coordinate system of a Transform.
+type Point struct {
+	X, Y float32
+}
+
+// FillRounded fills the rectangle with the given color, rounding its corners
+// with the given radius in pixels.
+func (r Rectangle) FillRounded(renderer Renderer, radius float32,
+	color api.RGBA) {
+	renderer.FillRoundedRect(r.Transformation(), radius, color)
+}
+
+// FillEllipse fills the ellipse inscribed into the rectangle with the given
+// color.
+func (r Rectangle) FillEllipse(renderer Renderer, color api.RGBA) {
+	renderer.FillEllipse(r.Transformation(), color)
+}
+
+// StrokeEllipse draws the outline of the ellipse inscribed into the rectangle
+// with the given width in pixels and color.
+func (r Rectangle) StrokeEllipse(renderer Renderer, width float32,
+	color api.RGBA) {
+	renderer.StrokeEllipse(r.Transformation(), width, color)
+}
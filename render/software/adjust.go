@@ -0,0 +1,44 @@
+package software
+
+import "github.com/QuestScreen/api/render"
+
+// luminance returns the gray value of the given color with Rec. 601 weights.
+func luminance(r, g, b float32) float32 {
+	return 0.299*r + 0.587*g + 0.114*b
+}
+
+// adjustColor applies the color adjustments of opts to the premultiplied
+// color c, in the order of the fields of DrawOptions.
+func adjustColor(c rgba, opts render.DrawOptions) rgba {
+	a := c[3]
+	if a == 0 {
+		return c
+	}
+	// adjustments operate on straight colors.
+	r, g, b := c[0]/a, c[1]/a, c[2]/a
+	if opts.Tint.A != 0 {
+		f := float32(opts.Tint.A) / 255
+		r *= 1 - f + f*float32(opts.Tint.R)/255
+		g *= 1 - f + f*float32(opts.Tint.G)/255
+		b *= 1 - f + f*float32(opts.Tint.B)/255
+	}
+	if opts.Grayscale != 0 {
+		l := luminance(r, g, b)
+		f := minFloat(maxFloat(opts.Grayscale, 0), 1)
+		r, g, b = r+(l-r)*f, g+(l-g)*f, b+(l-b)*f
+	}
+	r, g, b = r+opts.Brightness, g+opts.Brightness, b+opts.Brightness
+	if opts.Contrast != 0 {
+		f := maxFloat(1+opts.Contrast, 0)
+		r, g, b = 0.5+(r-0.5)*f, 0.5+(g-0.5)*f, 0.5+(b-0.5)*f
+	}
+	if opts.Saturation != 0 {
+		l := luminance(r, g, b)
+		f := maxFloat(1+opts.Saturation, 0)
+		r, g, b = l+(r-l)*f, l+(g-l)*f, l+(b-l)*f
+	}
+	clamp := func(v float32) float32 {
+		return minFloat(maxFloat(v, 0), 1) * a
+	}
+	return rgba{clamp(r), clamp(g), clamp(b), a}
+}
@@ -0,0 +1,153 @@
+package software
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/QuestScreen/api/render"
+)
+
+// minFrameDelay is the shortest delay of a GIF frame. Shorter delays, which
+// are common in GIF files, are replaced by defaultFrameDelay as browsers do.
+const (
+	minFrameDelay     = 20 * time.Millisecond
+	defaultFrameDelay = 100 * time.Millisecond
+)
+
+// limits of animated images. Since every frame is composed into a full copy
+// of the canvas, the number of pixels of all frames together is limited as
+// well.
+const (
+	maxAnimationSize   = 1 << 14
+	maxAnimationFrames = 1 << 10
+	maxAnimationPixels = 1 << 26
+)
+
+// checkAnimationSize returns an error if an animation with the given canvas
+// size and number of frames exceeds the limits.
+func checkAnimationSize(width, height int64, frames int) error {
+	if width <= 0 || height <= 0 || width > maxAnimationSize ||
+		height > maxAnimationSize {
+		return errors.New("animation canvas size outside of supported range")
+	}
+	if frames > maxAnimationFrames ||
+		int64(frames)*width*height > maxAnimationPixels {
+		return errors.New("animation has too many frames")
+	}
+	return nil
+}
+
+func frameDelay(d time.Duration) time.Duration {
+	if d < minFrameDelay {
+		return defaultFrameDelay
+	}
+	return d
+}
+
+// LoadAnimatedImageFile loads an animated image from the given URL, which must
+// have the file scheme.
+func (r *Renderer) LoadAnimatedImageFile(path *url.URL,
+	scaleDownToOutput bool) (render.AnimatedImage, error) {
+	if path.Scheme != "file" {
+		return render.AnimatedImage{}, errors.New(
+			"unsupported URL scheme: " + path.Scheme)
+	}
+	data, err := ioutil.ReadFile(path.Path)
+	if err != nil {
+		return render.AnimatedImage{}, err
+	}
+	return r.LoadAnimatedImageMem(data, scaleDownToOutput)
+}
+
+// LoadAnimatedImageMem decodes an animated image in GIF or APNG format. Other
+// formats supported by LoadImageMem yield a single frame. Like with
+// LoadImageMem, the frames are rotated according to the image's EXIF
+// orientation.
+func (r *Renderer) LoadAnimatedImageMem(data []byte,
+	scaleDownToOutput bool) (render.AnimatedImage, error) {
+	var frames []image.Image
+	var delays []time.Duration
+	var plays int
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		frames, delays, plays, err = decodeGIF(data)
+	case isAPNG(data):
+		frames, delays, plays, err = decodeAPNG(data)
+	default:
+		var img image.Image
+		img, _, err = image.Decode(bytes.NewReader(data))
+		frames, delays = []image.Image{img}, []time.Duration{0}
+	}
+	if err != nil {
+		return render.AnimatedImage{}, err
+	}
+	orientation := exifOrientation(data)
+	ret := render.AnimatedImage{Frames: make([]render.Frame, len(frames)),
+		Plays: plays}
+	for i := range frames {
+		img := r.upload(orient(frames[i], orientation), scaleDownToOutput)
+		ret.Frames[i] = render.Frame{Image: img,
+			Region: render.Rectangle{Width: img.Width, Height: img.Height},
+			Delay:  delays[i]}
+	}
+	return ret, nil
+}
+
+// decodeGIF composes the frames of a GIF animation.
+func decodeGIF(data []byte) (frames []image.Image, delays []time.Duration,
+	plays int, err error) {
+	// check the canvas size before decoding the frames, which are allocated
+	// with up to the canvas size each.
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := checkAnimationSize(int64(config.Width), int64(config.Height),
+		1); err != nil {
+		return nil, nil, 0, err
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := checkAnimationSize(int64(g.Config.Width), int64(g.Config.Height),
+		len(g.Image)); err != nil {
+		return nil, nil, 0, err
+	}
+	switch {
+	case g.LoopCount == -1:
+		plays = 1
+	case g.LoopCount > 0:
+		plays = g.LoopCount + 1
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = clone(canvas)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		frames = append(frames, clone(canvas))
+		delays = append(delays, frameDelay(
+			time.Duration(g.Delay[i])*10*time.Millisecond))
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{},
+				draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return frames, delays, plays, nil
+}
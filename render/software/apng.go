@@ -0,0 +1,219 @@
+package software
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+// APNG support: image/png decodes only the default image of an APNG file.
+// Each animation frame is therefore converted into a standalone PNG stream
+// and decoded with image/png, then composed according to the frame control
+// chunk.
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+func readChunks(data []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("not a PNG file")
+	}
+	var ret []pngChunk
+	data = data[len(pngSignature):]
+	for len(data) >= 12 {
+		length := binary.BigEndian.Uint32(data)
+		if uint64(length)+12 > uint64(len(data)) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		ret = append(ret, pngChunk{kind: string(data[4:8]),
+			data: data[8 : 8+length]})
+		data = data[12+length:]
+	}
+	return ret, nil
+}
+
+func isAPNG(data []byte) bool {
+	chunks, err := readChunks(data)
+	if err != nil {
+		return false
+	}
+	for _, c := range chunks {
+		switch c.kind {
+		case "acTL":
+			return true
+		case "IDAT":
+			return false
+		}
+	}
+	return false
+}
+
+func writeChunk(b *bytes.Buffer, kind string, data []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	b.Write(length[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(data)
+	b.WriteString(kind)
+	b.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	b.Write(sum[:])
+}
+
+// frameControl is the content of an fcTL chunk.
+type frameControl struct {
+	bounds  image.Rectangle
+	delay   time.Duration
+	dispose byte
+	blend   byte
+}
+
+const (
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+	apngBlendOver         = 1
+)
+
+func parseFrameControl(data []byte) (frameControl, error) {
+	if len(data) != 26 {
+		return frameControl{}, errors.New("invalid fcTL chunk")
+	}
+	be := binary.BigEndian
+	var values [4]int
+	for i := range values {
+		v := be.Uint32(data[4+4*i:])
+		if v > maxAnimationSize {
+			return frameControl{}, errors.New("fcTL chunk exceeds maximum size")
+		}
+		values[i] = int(v)
+	}
+	width, height, x, y := values[0], values[1], values[2], values[3]
+	num, den := be.Uint16(data[20:]), be.Uint16(data[22:])
+	if den == 0 {
+		den = 100
+	}
+	return frameControl{bounds: image.Rect(x, y, x+width, y+height),
+		delay:   time.Duration(num) * time.Second / time.Duration(den),
+		dispose: data[24], blend: data[25]}, nil
+}
+
+// apngFrame is a frame that has been read but not yet decoded.
+type apngFrame struct {
+	control frameControl
+	data    bytes.Buffer
+}
+
+// decodeAPNG composes the frames of an APNG animation.
+func decodeAPNG(data []byte) (frames []image.Image, delays []time.Duration,
+	plays int, err error) {
+	chunks, err := readChunks(data)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	var header []byte
+	// shared contains the chunks before the image data that apply to all
+	// frames, e.g. the palette.
+	var shared []pngChunk
+	var raw []*apngFrame
+	var current *apngFrame
+	for _, c := range chunks {
+		switch c.kind {
+		case "IHDR":
+			header = c.data
+		case "acTL":
+			if len(c.data) != 8 {
+				return nil, nil, 0, errors.New("invalid acTL chunk")
+			}
+			plays = int(binary.BigEndian.Uint32(c.data[4:]))
+		case "fcTL":
+			control, err := parseFrameControl(c.data)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			current = &apngFrame{control: control}
+			raw = append(raw, current)
+		case "IDAT":
+			// image data without preceding fcTL is not part of the animation.
+			if current != nil {
+				current.data.Write(c.data)
+			}
+		case "fdAT":
+			if current == nil || len(c.data) < 4 {
+				return nil, nil, 0, errors.New("invalid fdAT chunk")
+			}
+			current.data.Write(c.data[4:])
+		case "IEND":
+		default:
+			if len(raw) == 0 {
+				shared = append(shared, c)
+			}
+		}
+	}
+	if len(header) != 13 || len(raw) == 0 {
+		return nil, nil, 0, errors.New("invalid APNG file")
+	}
+	be := binary.BigEndian
+	width, height := be.Uint32(header), be.Uint32(header[4:])
+	if err := checkAnimationSize(int64(width), int64(height),
+		len(raw)); err != nil {
+		return nil, nil, 0, err
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	for _, f := range raw {
+		if f.control.bounds.Empty() || !f.control.bounds.In(canvas.Bounds()) {
+			return nil, nil, 0, errors.New("APNG frame outside of canvas")
+		}
+	}
+	for i, f := range raw {
+		var stream bytes.Buffer
+		stream.Write(pngSignature)
+		frameHeader := append([]byte(nil), header...)
+		be.PutUint32(frameHeader, uint32(f.control.bounds.Dx()))
+		be.PutUint32(frameHeader[4:], uint32(f.control.bounds.Dy()))
+		writeChunk(&stream, "IHDR", frameHeader)
+		for _, c := range shared {
+			writeChunk(&stream, c.kind, c.data)
+		}
+		writeChunk(&stream, "IDAT", f.data.Bytes())
+		writeChunk(&stream, "IEND", nil)
+		img, err := png.Decode(&stream)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		var previous *image.RGBA
+		dispose := f.control.dispose
+		if dispose == apngDisposePrevious && i == 0 {
+			dispose = apngDisposeBackground
+		}
+		if dispose == apngDisposePrevious {
+			previous = clone(canvas)
+		}
+		op := draw.Src
+		if f.control.blend == apngBlendOver {
+			op = draw.Over
+		}
+		draw.Draw(canvas, f.control.bounds, img, img.Bounds().Min, op)
+		frames = append(frames, clone(canvas))
+		// unlike in GIF files, a delay of 0 means as fast as possible.
+		delays = append(delays, f.control.delay)
+		switch dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, f.control.bounds, image.Transparent, image.Point{},
+				draw.Src)
+		case apngDisposePrevious:
+			canvas = previous
+		}
+	}
+	return frames, delays, plays, nil
+}
@@ -0,0 +1,121 @@
+package software
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+type canvas struct {
+	r        *Renderer
+	tex      *image.RGBA
+	previous *image.RGBA
+	// clip is the clip state of the previous target.
+	clip     clipState
+	hasAlpha bool
+	closed   bool
+}
+
+func (c *canvas) Finish() render.Image {
+	if c.closed {
+		return render.EmptyImage()
+	}
+	c.closed = true
+	c.r.target, c.r.clip = c.previous, c.clip
+	return c.r.register(c.tex, c.hasAlpha)
+}
+
+func (c *canvas) Close() {
+	if !c.closed {
+		c.closed = true
+		c.r.target, c.r.clip = c.previous, c.clip
+	}
+}
+
+// CreateCanvas creates a canvas filled with the given background. Borders are
+// drawn in Options.BorderColor and have a width of Unit().
+func (r *Renderer) CreateCanvas(innerWidth, innerHeight int32,
+	bg api.Background, borders render.Directions) (render.Canvas,
+	render.Rectangle) {
+	return r.CreateFramedCanvas(innerWidth, innerHeight, bg,
+		render.DirectionalBorders(borders, r.unit, r.opts.BorderColor))
+}
+
+// CreateFramedCanvas creates a canvas filled with the given background and
+// draws the given borders.
+func (r *Renderer) CreateFramedCanvas(innerWidth, innerHeight int32,
+	bg api.Background, borders render.Borders) (render.Canvas,
+	render.Rectangle) {
+	insets := borders.Insets()
+	area := render.Rectangle{Width: innerWidth, Height: innerHeight}.Outset(
+		insets)
+	area.X, area.Y = 0, 0
+	c := &canvas{r: r, previous: r.target, clip: r.clip,
+		tex: image.NewRGBA(image.Rect(0, 0, int(area.Width), int(area.Height)))}
+	c.hasAlpha = bg.Primary.A != 255 ||
+		(bg.TextureIndex != -1 && bg.Secondary.A != 255)
+	if bg.Gradient.Active() {
+		c.hasAlpha = bg.TextureIndex != -1 && bg.Secondary.A != 255
+		for _, stop := range bg.Gradient.UsedStops() {
+			c.hasAlpha = c.hasAlpha || stop.Color.A != 255
+		}
+	}
+	r.target, r.clip = c.tex, unclipped(c.tex)
+	r.fillBackground(bg)
+	rest := area
+	for _, edge := range [4]struct {
+		d render.Directions
+		b render.Border
+	}{{render.North, borders.Top}, {render.South, borders.Bottom},
+		{render.East, borders.Right}, {render.West, borders.Left}} {
+		if edge.b.Width <= 0 {
+			continue
+		}
+		var carved render.Rectangle
+		carved, rest = rest.Carve(edge.d, edge.b.Width)
+		r.FillRect(carved.Transformation(), edge.b.Color)
+	}
+	return c, area.Inset(insets)
+}
+
+// fillBackground fills the current target with the given background.
+// If the background has an active gradient, it replaces the primary color.
+func (r *Renderer) fillBackground(bg api.Background) {
+	primary := premultiply(bg.Primary)
+	hasTexture := bg.TextureIndex >= 0 && bg.TextureIndex < len(r.opts.Textures)
+	if !hasTexture && !bg.Gradient.Active() {
+		fill(r.target, primary)
+		return
+	}
+	b := r.target.Rect
+	var eval gradient
+	if bg.Gradient.Active() {
+		eval = newGradient(bg.Gradient, float32(b.Dx()), float32(b.Dy()))
+	}
+	secondary := premultiply(bg.Secondary)
+	var mask image.Image
+	if hasTexture {
+		mask = r.opts.Textures[bg.TextureIndex]
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			base := primary
+			if bg.Gradient.Active() {
+				// image rows start at the top, the gradient's y axis points upwards.
+				base = eval.at(float32(x-b.Min.X)+0.5-float32(b.Dx())/2,
+					float32(b.Max.Y-y)-0.5-float32(b.Dy())/2)
+			}
+			if !hasTexture {
+				setPixel(r.target, x, y, base)
+				continue
+			}
+			mb := mask.Bounds()
+			gray := color.GrayModel.Convert(mask.At(mb.Min.X+(x-b.Min.X)%mb.Dx(),
+				mb.Min.Y+(y-b.Min.Y)%mb.Dy())).(color.Gray)
+			m := float32(gray.Y) / 255
+			setPixel(r.target, x, y, base.scale(1-m).plus(secondary.scale(m)))
+		}
+	}
+}
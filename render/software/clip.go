@@ -0,0 +1,69 @@
+package software
+
+import (
+	"image"
+
+	"github.com/QuestScreen/api/render"
+)
+
+// clipState holds the clip areas of a target. Coordinates are relative to the
+// target and interpreted like OpenGL does, i.e. (0, 0) is the lower left
+// corner.
+type clipState struct {
+	// area is the active clip area. Pixels outside of it are not drawn.
+	area image.Rectangle
+	// stack holds the areas that were active before each PushClip.
+	stack []image.Rectangle
+}
+
+func unclipped(target *image.RGBA) clipState {
+	return clipState{area: image.Rect(0, 0, target.Rect.Dx(), target.Rect.Dy())}
+}
+
+// PushClip intersects the active clip area with the given area.
+func (r *Renderer) PushClip(area render.Rectangle) {
+	r.clip.stack = append(r.clip.stack, r.clip.area)
+	r.clip.area = r.clip.area.Intersect(image.Rect(int(area.X), int(area.Y),
+		int(area.X+area.Width), int(area.Y+area.Height)))
+}
+
+// PopClip restores the clip area that was active before the last PushClip.
+func (r *Renderer) PopClip() {
+	if n := len(r.clip.stack); n > 0 {
+		r.clip.area = r.clip.stack[n-1]
+		r.clip.stack = r.clip.stack[:n-1]
+	}
+}
+
+// compose blends c into the pixel of the current target at the given
+// position with the given mode, unless it lies outside of the clip area.
+// Coordinates are interpreted like OpenGL does, see rasterize.
+func (r *Renderer) compose(x, y int, c rgba, mode render.BlendMode) {
+	if !(image.Point{x, y}).In(r.clip.area) {
+		return
+	}
+	target := r.target
+	px, py := target.Rect.Min.X+x, target.Rect.Max.Y-1-y
+	setPixel(target, px, py, c.blend(pixel(target, px, py), mode))
+}
+
+// DrawImageMasked draws the given image on the transformed unit square,
+// weighted with the alpha channel of mask.
+func (r *Renderer) DrawImageMasked(image, mask render.Image,
+	t render.Transform, alpha uint8) {
+	tex, ok := r.textures[image.TextureID]
+	if image.IsEmpty() || !ok {
+		return
+	}
+	maskTex, ok := r.textures[mask.TextureID]
+	if mask.IsEmpty() || !ok {
+		return
+	}
+	f := float32(alpha) / 255
+	r.rasterize(t, func(u, v float32) (rgba, bool) {
+		m := samplePixel(maskTex, (u+0.5)*float32(maskTex.Rect.Dx()),
+			(0.5-v)*float32(maskTex.Rect.Dy()))
+		return samplePixel(tex, (u+0.5)*float32(tex.Rect.Dx()),
+			(0.5-v)*float32(tex.Rect.Dy())).scale(f * m[3]), true
+	})
+}
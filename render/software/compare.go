@@ -0,0 +1,43 @@
+package software
+
+import "image"
+
+// Difference compares two images pixel by pixel and returns the number of
+// pixels where at least one channel differs by more than tolerance.
+// Pixels outside of one image's bounds always count as different.
+func Difference(a, b image.Image, tolerance uint8) int {
+	ab, bb := a.Bounds(), b.Bounds()
+	width, height := ab.Dx(), ab.Dy()
+	if bb.Dx() > width {
+		width = bb.Dx()
+	}
+	if bb.Dy() > height {
+		height = bb.Dy()
+	}
+	tol := uint32(tolerance) * 0x101
+	count := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pa := image.Pt(ab.Min.X+x, ab.Min.Y+y)
+			pb := image.Pt(bb.Min.X+x, bb.Min.Y+y)
+			if !pa.In(ab) || !pb.In(bb) {
+				count++
+				continue
+			}
+			r1, g1, b1, a1 := a.At(pa.X, pa.Y).RGBA()
+			r2, g2, b2, a2 := b.At(pb.X, pb.Y).RGBA()
+			if absDiff(r1, r2) > tol || absDiff(g1, g2) > tol ||
+				absDiff(b1, b2) > tol || absDiff(a1, a2) > tol {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,207 @@
+package software
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// textEffects contains the effects of an api.Font with lengths in pixels.
+// Shadow offsets are in image coordinates, i.e. positive y is downwards.
+type textEffects struct {
+	outline, glow, shadowX, shadowY float32
+	font                            api.Font
+}
+
+func (r *Renderer) effects(f api.Font) textEffects {
+	unit := float32(r.unit)
+	ret := textEffects{font: f}
+	if f.Outline.Color.A != 0 {
+		ret.outline = f.Outline.Width * unit
+	}
+	if f.Glow.Color.A != 0 {
+		ret.glow = f.Glow.Radius * unit
+	}
+	if f.Shadow.Color.A != 0 {
+		ret.shadowX = f.Shadow.OffsetX * unit
+		ret.shadowY = -f.Shadow.OffsetY * unit
+	}
+	return ret
+}
+
+// padding is the space around a text's glyphs that is required for drawing
+// its effects.
+type padding struct {
+	left, top, right, bottom int
+}
+
+func ceilMax(values ...float32) int {
+	ret := 0
+	for _, v := range values {
+		if c := int(math.Ceil(float64(v))); c > ret {
+			ret = c
+		}
+	}
+	return ret
+}
+
+func (e textEffects) padding() padding {
+	return padding{
+		left:   ceilMax(e.outline, e.glow, -e.shadowX),
+		top:    ceilMax(e.outline, e.glow, -e.shadowY),
+		right:  ceilMax(e.outline, e.glow, e.shadowX),
+		bottom: ceilMax(e.outline, e.glow, e.shadowY)}
+}
+
+func (p padding) union(o padding) padding {
+	return padding{
+		left:   ceilMax(float32(p.left), float32(o.left)),
+		top:    ceilMax(float32(p.top), float32(o.top)),
+		right:  ceilMax(float32(p.right), float32(o.right)),
+		bottom: ceilMax(float32(p.bottom), float32(o.bottom))}
+}
+
+// textRun is a text drawn with a single font at a given position.
+type textRun struct {
+	face    font.Face
+	effects textEffects
+	text    string
+	// dot is the start of the baseline relative to the upper left corner of
+	// the text's area, excluding padding.
+	dot fixed.Point26_6
+}
+
+func uniform(c api.RGBA) *image.Uniform {
+	return image.NewUniform(color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A})
+}
+
+// renderRuns renders the given runs into an image whose size is the given
+// size extended by pad. Effects are drawn in layers so that no effect covers
+// the glyphs of another run: glows first, then shadows, outlines and finally
+// the glyphs themselves.
+func (r *Renderer) renderRuns(width, height int, pad padding,
+	runs []textRun) render.Image {
+	if width <= 0 || height <= 0 {
+		return render.EmptyImage()
+	}
+	tex := image.NewRGBA(image.Rect(0, 0, width+pad.left+pad.right,
+		height+pad.top+pad.bottom))
+	masks := make([]*image.Alpha, len(runs))
+	for i, run := range runs {
+		masks[i] = image.NewAlpha(tex.Rect)
+		d := font.Drawer{Dst: masks[i], Face: run.face, Src: image.Opaque,
+			Dot: run.dot.Add(fixed.P(pad.left, pad.top))}
+		d.DrawString(run.text)
+	}
+	for i, run := range runs {
+		if e := run.effects; e.glow > 0 {
+			glow := blur(dilate(masks[i], e.glow/2), e.glow/2)
+			draw.DrawMask(tex, tex.Rect, uniform(e.font.Glow.Color), image.ZP,
+				glow, image.ZP, draw.Over)
+		}
+	}
+	for i, run := range runs {
+		if e := run.effects; e.shadowX != 0 || e.shadowY != 0 {
+			offset := image.Pt(-int(math.Round(float64(e.shadowX))),
+				-int(math.Round(float64(e.shadowY))))
+			draw.DrawMask(tex, tex.Rect, uniform(e.font.Shadow.Color), image.ZP,
+				masks[i], offset, draw.Over)
+		}
+	}
+	for i, run := range runs {
+		if e := run.effects; e.outline > 0 {
+			draw.DrawMask(tex, tex.Rect, uniform(e.font.Outline.Color), image.ZP,
+				dilate(masks[i], e.outline), image.ZP, draw.Over)
+		}
+	}
+	for i, run := range runs {
+		draw.DrawMask(tex, tex.Rect, uniform(run.effects.font.Color), image.ZP,
+			masks[i], image.ZP, draw.Over)
+	}
+	return r.register(tex, true)
+}
+
+// dilate grows the covered area of the given mask by radius pixels.
+func dilate(mask *image.Alpha, radius float32) *image.Alpha {
+	ret := image.NewAlpha(mask.Rect)
+	reach := int(math.Ceil(float64(radius)))
+	b := mask.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var value float32
+			for dy := -reach; dy <= reach; dy++ {
+				for dx := -reach; dx <= reach; dx++ {
+					p := image.Pt(x+dx, y+dy)
+					if !p.In(b) {
+						continue
+					}
+					dist := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+					weight := radius + 0.5 - dist
+					if weight <= 0 {
+						continue
+					} else if weight > 1 {
+						weight = 1
+					}
+					if v := float32(mask.AlphaAt(p.X, p.Y).A) * weight; v > value {
+						value = v
+					}
+				}
+			}
+			ret.SetAlpha(x, y, color.Alpha{A: uint8(value)})
+		}
+	}
+	return ret
+}
+
+// blur applies three passes of a box blur with the given radius, which
+// approximates a gaussian blur.
+func blur(mask *image.Alpha, radius float32) *image.Alpha {
+	k := int(math.Ceil(float64(radius)))
+	if k == 0 {
+		return mask
+	}
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	values := make([]float32, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			values[y*w+x] = float32(mask.Pix[mask.PixOffset(
+				mask.Rect.Min.X+x, mask.Rect.Min.Y+y)])
+		}
+	}
+	tmp := make([]float32, w*h)
+	for pass := 0; pass < 3; pass++ {
+		boxBlur(values, tmp, w, h, k, 1, w)
+		boxBlur(tmp, values, h, w, k, w, 1)
+	}
+	ret := image.NewAlpha(mask.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ret.Pix[ret.PixOffset(ret.Rect.Min.X+x, ret.Rect.Min.Y+y)] =
+				uint8(values[y*w+x] + 0.5)
+		}
+	}
+	return ret
+}
+
+// boxBlur blurs lines of length n along the given step; lines start at
+// multiples of lineStep. count is the number of lines.
+func boxBlur(src, dst []float32, n, count, k, step, lineStep int) {
+	for line := 0; line < count; line++ {
+		base := line * lineStep
+		for i := 0; i < n; i++ {
+			var sum float32
+			for j := i - k; j <= i+k; j++ {
+				if j >= 0 && j < n {
+					sum += src[base+j*step]
+				}
+			}
+			dst[base+i*step] = sum / float32(2*k+1)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package software
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// exifOrientation returns the value of the EXIF orientation tag of the given
+// JPEG or PNG data, or 1 (no transformation) if there is none.
+func exifOrientation(data []byte) int {
+	if bytes.HasPrefix(data, pngSignature) {
+		chunks, err := readChunks(data)
+		if err != nil {
+			return 1
+		}
+		for _, c := range chunks {
+			if c.kind == "eXIf" {
+				return tiffOrientation(c.data)
+			}
+		}
+		return 1
+	}
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return 1
+	}
+	data = data[2:]
+	// walk the JPEG segments until the image data starts.
+	for len(data) >= 4 && data[0] == 0xFF {
+		marker := data[1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[2:]))
+		if length < 2 || length+2 > len(data) {
+			break
+		}
+		segment := data[4 : length+2]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		data = data[length+2:]
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of the given
+// TIFF structure.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// orient transforms src so that it is displayed upright according to the
+// given EXIF orientation.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, src, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+	ret := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := rgba.Rect.Dx(), rgba.Rect.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flipped horizontally
+				sx, sy = sw-1-x, y
+			case 3: // rotated by 180°
+				sx, sy = sw-1-x, sh-1-y
+			case 4: // flipped vertically
+				sx, sy = x, sh-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs rotation by 90° clockwise
+				sx, sy = y, sh-1-x
+			case 7: // transversed
+				sx, sy = sw-1-y, sh-1-x
+			case 8: // needs rotation by 90° counterclockwise
+				sx, sy = sw-1-y, x
+			}
+			copy(ret.Pix[ret.PixOffset(x, y):ret.PixOffset(x, y)+4],
+				rgba.Pix[rgba.PixOffset(sx, sy):rgba.PixOffset(sx, sy)+4])
+		}
+	}
+	return ret
+}
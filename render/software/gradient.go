@@ -0,0 +1,85 @@
+package software
+
+import (
+	"math"
+	"sort"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+type gradientStop struct {
+	offset float32
+	color  rgba
+}
+
+// gradient evaluates an api.Gradient on an area with a given size in pixels.
+type gradient struct {
+	stops  []gradientStop
+	radial bool
+	// dx, dy is the direction of a linear gradient, scaled so that the dot
+	// product with a position yields the offset relative to the center.
+	dx, dy float32
+	// extent is the distance from the center to the corners of a radial
+	// gradient.
+	extent float32
+}
+
+func newGradient(g api.Gradient, width, height float32) gradient {
+	stops := g.UsedStops()
+	ret := gradient{stops: make([]gradientStop, len(stops)),
+		radial: g.Kind == api.RadialGradient}
+	for i, s := range stops {
+		ret.stops[i] = gradientStop{offset: s.Offset, color: premultiply(s.Color)}
+	}
+	sort.SliceStable(ret.stops, func(i, j int) bool {
+		return ret.stops[i].offset < ret.stops[j].offset
+	})
+	if ret.radial {
+		ret.extent = length(width/2, height/2)
+	} else {
+		angle := float64(g.Angle) * math.Pi / 180
+		dx, dy := float32(math.Cos(angle)), float32(math.Sin(angle))
+		// the gradient ends at the corners that are farthest along the direction
+		extent := absFloat(dx*width/2) + absFloat(dy*height/2)
+		if extent > 0 {
+			ret.dx, ret.dy = dx/extent/2, dy/extent/2
+		}
+	}
+	return ret
+}
+
+// at returns the color at the given position relative to the area's center.
+func (g gradient) at(x, y float32) rgba {
+	var offset float32
+	if g.radial {
+		if g.extent > 0 {
+			offset = length(x, y) / g.extent
+		}
+	} else {
+		offset = x*g.dx + y*g.dy + 0.5
+	}
+	if offset <= g.stops[0].offset {
+		return g.stops[0].color
+	}
+	for i := 1; i < len(g.stops); i++ {
+		if offset < g.stops[i].offset {
+			prev := g.stops[i-1]
+			f := (offset - prev.offset) / (g.stops[i].offset - prev.offset)
+			return prev.color.scale(1 - f).plus(g.stops[i].color.scale(f))
+		}
+	}
+	return g.stops[len(g.stops)-1].color
+}
+
+// FillGradient fills the transformed unit square with the given gradient.
+func (r *Renderer) FillGradient(t render.Transform, g api.Gradient) {
+	if !g.Active() {
+		return
+	}
+	width, height := length(t[0], t[1]), length(t[2], t[3])
+	eval := newGradient(g, width, height)
+	r.rasterize(t, func(u, v float32) (rgba, bool) {
+		return eval.at(u*width, v*height), true
+	})
+}
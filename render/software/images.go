@@ -0,0 +1,190 @@
+package software
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/draw"
+	"io/ioutil"
+	"math"
+	"net/url"
+
+	// image formats supported by the image loading functions
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/QuestScreen/api/render"
+	xdraw "golang.org/x/image/draw"
+)
+
+// LoadImageFile loads an image file from the given URL, which must have the
+// file scheme.
+func (r *Renderer) LoadImageFile(path *url.URL,
+	scaleDownToOutput bool) (render.Image, error) {
+	return r.LoadImageFileWith(path,
+		render.ImageOptions{ScaleDownToOutput: scaleDownToOutput})
+}
+
+// LoadImageMem decodes an image in PNG, JPEG or GIF format.
+func (r *Renderer) LoadImageMem(data []byte,
+	scaleDownToOutput bool) (render.Image, error) {
+	return r.LoadImageMemWith(data,
+		render.ImageOptions{ScaleDownToOutput: scaleDownToOutput})
+}
+
+// LoadImageFileWith loads an image file from the given URL, which must have
+// the file scheme.
+func (r *Renderer) LoadImageFileWith(path *url.URL,
+	opts render.ImageOptions) (render.Image, error) {
+	decoded, err := r.DecodeImageFile(context.Background(), path, opts)
+	if err != nil {
+		return render.EmptyImage(), err
+	}
+	return r.UploadImage(decoded), nil
+}
+
+// LoadImageMemWith decodes an image in PNG, JPEG or GIF format.
+func (r *Renderer) LoadImageMemWith(data []byte,
+	opts render.ImageOptions) (render.Image, error) {
+	decoded, err := r.decodeData(context.Background(), data, opts)
+	if err != nil {
+		return render.EmptyImage(), err
+	}
+	return r.UploadImage(decoded), nil
+}
+
+// DecodeImageFile reads and decodes an image file from the given URL, which
+// must have the file scheme. It is safe to call from any goroutine.
+func (r *Renderer) DecodeImageFile(ctx context.Context, path *url.URL,
+	opts render.ImageOptions) (render.DecodedImage, error) {
+	if path.Scheme != "file" {
+		return render.DecodedImage{}, errors.New(
+			"unsupported URL scheme: " + path.Scheme)
+	}
+	data, err := ioutil.ReadFile(path.Path)
+	if err != nil {
+		return render.DecodedImage{}, err
+	}
+	return r.decodeData(ctx, data, opts)
+}
+
+func (r *Renderer) decodeData(ctx context.Context, data []byte,
+	opts render.ImageOptions) (render.DecodedImage, error) {
+	if err := ctx.Err(); err != nil {
+		return render.DecodedImage{}, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return render.DecodedImage{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return render.DecodedImage{}, err
+	}
+	return r.decode(orient(src, exifOrientation(data)), opts), nil
+}
+
+// UploadImage registers the decoded pixel data as texture.
+func (r *Renderer) UploadImage(decoded render.DecodedImage) render.Image {
+	if decoded.Pixels == nil {
+		return render.EmptyImage()
+	}
+	return r.register(decoded.Pixels, decoded.HasAlpha)
+}
+
+// upload converts the given image to a texture, scaling it down if necessary.
+func (r *Renderer) upload(src image.Image, scaleDownToOutput bool) render.Image {
+	return r.UploadImage(r.decode(src,
+		render.ImageOptions{ScaleDownToOutput: scaleDownToOutput}))
+}
+
+// targetSize calculates the part of an image with the given bounds that is
+// used, and the size it is scaled to, according to the given options.
+func (r *Renderer) targetSize(b image.Rectangle,
+	opts render.ImageOptions) (crop image.Rectangle, width, height int32) {
+	crop = b
+	width, height = int32(b.Dx()), int32(b.Dy())
+	if (opts.Width > 0 || opts.Height > 0) && width > 0 && height > 0 {
+		box := render.Rectangle{Width: opts.Width, Height: opts.Height}
+		if box.Width <= 0 {
+			box.Width = clampInt32((int64(box.Height)*int64(width) +
+				int64(height)/2) / int64(height))
+		} else if box.Height <= 0 {
+			box.Height = clampInt32((int64(box.Width)*int64(height) +
+				int64(width)/2) / int64(width))
+		}
+		switch opts.Fit {
+		case render.Cover:
+			// cut off the parts of the image that would lie outside of the box.
+			c := render.Rectangle{Width: width, Height: height}.Fit(
+				box.Width, box.Height, render.Contain)
+			crop = image.Rect(b.Min.X+int(c.X), b.Min.Y+int(c.Y),
+				b.Min.X+int(c.X+c.Width), b.Min.Y+int(c.Y+c.Height))
+			width, height = box.Width, box.Height
+		default:
+			fitted := box.Fit(width, height, opts.Fit)
+			width, height = fitted.Width, fitted.Height
+		}
+		if width < 1 {
+			width = 1
+		}
+		if height < 1 {
+			height = 1
+		}
+	}
+	maxWidth, maxHeight := r.opts.MaxTextureSize, r.opts.MaxTextureSize
+	if opts.ScaleDownToOutput {
+		if r.opts.Width < maxWidth {
+			maxWidth = r.opts.Width
+		}
+		if r.opts.Height < maxHeight {
+			maxHeight = r.opts.Height
+		}
+	}
+	if width > maxWidth || height > maxHeight {
+		// products are computed in int64 since they overflow int32 for large
+		// images.
+		if int64(width)*int64(maxHeight) > int64(height)*int64(maxWidth) {
+			height = int32(int64(height) * int64(maxWidth) / int64(width))
+			width = maxWidth
+		} else {
+			width = int32(int64(width) * int64(maxHeight) / int64(height))
+			height = maxHeight
+		}
+		if width < 1 {
+			width = 1
+		}
+		if height < 1 {
+			height = 1
+		}
+	}
+	return
+}
+
+// clampInt32 converts v to int32, saturating at the bounds of int32.
+func clampInt32(v int64) int32 {
+	if v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(v)
+}
+
+// decode converts the given image to premultiplied RGBA, scaling it according
+// to the given options. It only reads immutable options and is thus safe to
+// call from any goroutine.
+func (r *Renderer) decode(src image.Image,
+	opts render.ImageOptions) render.DecodedImage {
+	crop, width, height := r.targetSize(src.Bounds(), opts)
+	tex := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	if width == int32(crop.Dx()) && height == int32(crop.Dy()) {
+		draw.Draw(tex, tex.Rect, src, crop.Min, draw.Src)
+	} else {
+		xdraw.BiLinear.Scale(tex, tex.Rect, src, crop, xdraw.Src, nil)
+	}
+	hasAlpha := true
+	if o, ok := src.(interface{ Opaque() bool }); ok {
+		hasAlpha = !o.Opaque()
+	}
+	return render.DecodedImage{Pixels: tex, HasAlpha: hasAlpha}
+}
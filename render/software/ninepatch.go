@@ -0,0 +1,94 @@
+package software
+
+import (
+	"image"
+
+	"github.com/QuestScreen/api/render"
+)
+
+// patchAxis maps the pixels of one part of a nine-patch along one axis to the
+// source pixels. start and end are the part's bounds in the target, from and
+// to its bounds in the source image.
+type patchAxis struct {
+	start, end, from, to int
+	mode                 render.PatchMode
+	scale                float32
+}
+
+// source returns the source pixel for the given offset from start.
+func (a patchAxis) source(offset int) int {
+	size := a.to - a.from
+	if a.mode == render.Tile {
+		return a.from + int(float32(offset)/a.scale)%size
+	}
+	return clampInt(a.from+int((float32(offset)+0.5)*float32(size)/
+		float32(a.end-a.start)), a.from, a.to-1)
+}
+
+// DrawNinePatch draws each part of the nine-patch with its own mapping from
+// target to source pixels.
+func (r *Renderer) DrawNinePatch(patch render.NinePatch, area render.Rectangle,
+	alpha uint8) {
+	tex, ok := r.textures[patch.Image.TextureID]
+	if patch.Image.IsEmpty() || !ok || area.Empty() {
+		return
+	}
+	scale := patch.BorderScale
+	if scale == 0 {
+		scale = 1
+	}
+	b := patch.Borders(area)
+	in := patch.Insets
+	w, h := tex.Rect.Dx(), tex.Rect.Dy()
+	// columns are ordered from left to right, rows from top to bottom.
+	// Target rows are counted from the top of the area.
+	cols := [4]int{0, int(b.Left), int(area.Width - b.Right), int(area.Width)}
+	rows := [4]int{0, int(b.Top), int(area.Height - b.Bottom), int(area.Height)}
+	srcCols := [4]int{0, int(in.Left), w - int(in.Right), w}
+	srcRows := [4]int{0, int(in.Top), h - int(in.Bottom), h}
+	f := float32(alpha) / 255
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			x := patchAxis{start: cols[col], end: cols[col+1], from: srcCols[col],
+				to: srcCols[col+1], scale: scale}
+			y := patchAxis{start: rows[row], end: rows[row+1], from: srcRows[row],
+				to: srcRows[row+1], scale: scale}
+			if x.end <= x.start || y.end <= y.start || x.to <= x.from ||
+				y.to <= y.from {
+				continue
+			}
+			if col == 1 {
+				x.mode = patch.Edges
+				if row == 1 {
+					x.mode = patch.Center
+				}
+			}
+			if row == 1 {
+				y.mode = patch.Edges
+				if col == 1 {
+					y.mode = patch.Center
+				}
+			}
+			r.drawPatch(tex, area, x, y, f)
+		}
+	}
+}
+
+// drawPatch composes one part of a nine-patch over the current target.
+func (r *Renderer) drawPatch(tex *image.RGBA, area render.Rectangle,
+	x, y patchAxis, f float32) {
+	w, h := r.target.Rect.Dx(), r.target.Rect.Dy()
+	// top is the target's row containing the area's upper edge.
+	top := h - int(area.Y+area.Height)
+	x0 := clampInt(int(area.X)+x.start, 0, w)
+	x1 := clampInt(int(area.X)+x.end, 0, w)
+	y0 := clampInt(top+y.start, 0, h)
+	y1 := clampInt(top+y.end, 0, h)
+	for py := y0; py < y1; py++ {
+		sy := tex.Rect.Min.Y + y.source(py-top-y.start)
+		for px := x0; px < x1; px++ {
+			sx := tex.Rect.Min.X + x.source(px-int(area.X)-x.start)
+			r.compose(px, h-1-py, pixel(tex, sx, sy).scale(f), render.Normal)
+		}
+	}
+}
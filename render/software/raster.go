@@ -0,0 +1,178 @@
+package software
+
+import (
+	"image"
+	"math"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+// rgba is a premultiplied color with components in [0,1].
+type rgba [4]float32
+
+func premultiply(c api.RGBA) rgba {
+	a := float32(c.A) / 255
+	return rgba{float32(c.R) / 255 * a, float32(c.G) / 255 * a,
+		float32(c.B) / 255 * a, a}
+}
+
+func (c rgba) scale(f float32) rgba {
+	return rgba{c[0] * f, c[1] * f, c[2] * f, c[3] * f}
+}
+
+func (c rgba) plus(d rgba) rgba {
+	return rgba{c[0] + d[0], c[1] + d[1], c[2] + d[2], c[3] + d[3]}
+}
+
+// over composes c over d.
+func (c rgba) over(d rgba) rgba {
+	f := 1 - c[3]
+	return rgba{c[0] + d[0]*f, c[1] + d[1]*f, c[2] + d[2]*f, c[3] + d[3]*f}
+}
+
+func toByte(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	} else if v >= 1 {
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
+
+func pixel(img *image.RGBA, x, y int) rgba {
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+4]
+	return rgba{float32(p[0]) / 255, float32(p[1]) / 255, float32(p[2]) / 255,
+		float32(p[3]) / 255}
+}
+
+func setPixel(img *image.RGBA, x, y int, c rgba) {
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+4]
+	p[0], p[1], p[2], p[3] = toByte(c[0]), toByte(c[1]), toByte(c[2]),
+		toByte(c[3])
+}
+
+// samplePixel returns the pixel of img at the given position in pixels, where
+// (0, 0) is the upper left corner. Positions outside of img are clamped.
+func samplePixel(img *image.RGBA, x, y float32) rgba {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	px := clampInt(int(math.Floor(float64(x))), 0, w-1)
+	py := clampInt(int(math.Floor(float64(y))), 0, h-1)
+	return pixel(img, img.Rect.Min.X+px, img.Rect.Min.Y+py)
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+	return v
+}
+
+func minFloat(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func fill(img *image.RGBA, c rgba) {
+	b := img.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			setPixel(img, x, y, c)
+		}
+	}
+}
+
+func clone(img *image.RGBA) *image.RGBA {
+	ret := image.NewRGBA(image.Rect(0, 0, img.Rect.Dx(), img.Rect.Dy()))
+	for y := 0; y < ret.Rect.Dy(); y++ {
+		copy(ret.Pix[y*ret.Stride:(y+1)*ret.Stride],
+			img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):])
+	}
+	return ret
+}
+
+// blend combines c with d according to the given mode.
+func (c rgba) blend(d rgba, mode render.BlendMode) rgba {
+	switch mode {
+	case render.Additive:
+		return c.plus(d)
+	case render.Multiply:
+		var ret rgba
+		for i := 0; i < 3; i++ {
+			ret[i] = c[i]*d[i] + c[i]*(1-d[3]) + d[i]*(1-c[3])
+		}
+		ret[3] = c[3] + d[3] - c[3]*d[3]
+		return ret
+	case render.Screen:
+		var ret rgba
+		for i := range ret {
+			ret[i] = c[i] + d[i] - c[i]*d[i]
+		}
+		return ret
+	default:
+		return c.over(d)
+	}
+}
+
+// rasterize calls shade for each pixel of the current target whose center
+// lies inside the unit square transformed by t. u and v are the coordinates
+// of the pixel center relative to the untransformed unit square, i.e. in
+// [-0.5, 0.5). The returned color is composed over the pixel unless shade
+// returns false or the pixel lies outside of the clip area.
+//
+// The target's coordinates are interpreted like OpenGL does, i.e. (0, 0) is
+// the lower left corner.
+func (r *Renderer) rasterize(t render.Transform,
+	shade func(u, v float32) (rgba, bool)) {
+	r.rasterizeWith(t, render.Normal, shade)
+}
+
+// rasterizeWith works like rasterize, but combines the returned colors with
+// the target according to the given blend mode.
+func (r *Renderer) rasterizeWith(t render.Transform, mode render.BlendMode,
+	shade func(u, v float32) (rgba, bool)) {
+	w, h := r.target.Rect.Dx(), r.target.Rect.Dy()
+	minX, minY := float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY := float32(math.Inf(-1)), float32(math.Inf(-1))
+	for _, c := range [4][2]float32{{-0.5, -0.5}, {0.5, -0.5}, {-0.5, 0.5},
+		{0.5, 0.5}} {
+		x := t[0]*c[0] + t[2]*c[1] + t[4]
+		y := t[1]*c[0] + t[3]*c[1] + t[5]
+		minX, maxX = minFloat(minX, x), maxFloat(maxX, x)
+		minY, maxY = minFloat(minY, y), maxFloat(maxY, y)
+	}
+	x0 := clampInt(int(math.Floor(float64(minX))), 0, w)
+	x1 := clampInt(int(math.Ceil(float64(maxX))), 0, w)
+	y0 := clampInt(int(math.Floor(float64(minY))), 0, h)
+	y1 := clampInt(int(math.Ceil(float64(maxY))), 0, h)
+	inv := t.Invert()
+	for y := y0; y < y1; y++ {
+		cy := float32(y) + 0.5
+		for x := x0; x < x1; x++ {
+			cx := float32(x) + 0.5
+			u := inv[0]*cx + inv[2]*cy + inv[4]
+			v := inv[1]*cx + inv[3]*cy + inv[5]
+			if !(u >= -0.5 && u < 0.5 && v >= -0.5 && v < 0.5) {
+				continue
+			}
+			c, ok := shade(u, v)
+			if !ok {
+				continue
+			}
+			r.compose(x, y, c, mode)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package software
+
+import (
+	"image"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+// Options configures a software Renderer.
+type Options struct {
+	// Width and Height of the output in pixels.
+	Width, Height int32
+	// Fonts lists the available font families. FamilyIndex of api.Font indexes
+	// into this slice. If empty, the Go font family will be used as only family.
+	Fonts []FontFamily
+	// Textures lists the textures available for backgrounds. TextureIndex of
+	// api.Background indexes into this slice. Each texture is interpreted as
+	// grayscale mask; lighter pixels show more of the secondary color.
+	Textures []image.Image
+	// BorderColor is the color canvas borders are drawn with.
+	BorderColor api.RGBA
+	// MaxTextureSize is the maximum edge length of a texture and emulates
+	// GL_MAX_TEXTURE_SIZE. If 0, 8192 is used.
+	MaxTextureSize int32
+}
+
+// Renderer is a CPU-based implementation of render.Renderer that rasterizes
+// into an image.RGBA. It is meant to run module renderers in environments
+// without OpenGL, e.g. in unit tests.
+//
+// Its output is not identical to the OpenGL renderer of the main app (the
+// edges of shapes like ellipses, lines and polygons are anti-aliased by their
+// pixel coverage, but textured rectangles are not, and images are sampled with
+// nearest-neighbor interpolation), but it is deterministic and
+// layout-accurate, which makes it suitable for comparing pixel output.
+//
+// Like the OpenGL renderer, a Renderer must not be used concurrently.
+type Renderer struct {
+	opts     Options
+	unit     int32
+	screen   *image.RGBA
+	target   *image.RGBA
+	clip     clipState
+	textures map[uint32]*image.RGBA
+	nextID   uint32
+	fonts    fontCache
+}
+
+// New creates a new Renderer with the given options.
+// The output is initially filled with transparent black.
+func New(opts Options) *Renderer {
+	if opts.MaxTextureSize == 0 {
+		opts.MaxTextureSize = 8192
+	}
+	if len(opts.Fonts) == 0 {
+		opts.Fonts = []FontFamily{GoFontFamily()}
+	}
+	r := &Renderer{opts: opts,
+		screen:   image.NewRGBA(image.Rect(0, 0, int(opts.Width), int(opts.Height))),
+		textures: make(map[uint32]*image.RGBA), nextID: 1}
+	r.target = r.screen
+	r.clip = unclipped(r.screen)
+	if opts.Width < opts.Height {
+		r.unit = opts.Width / 144
+	} else {
+		r.unit = opts.Height / 144
+	}
+	if r.unit == 0 {
+		r.unit = 1
+	}
+	r.fonts.init(opts.Fonts)
+	return r
+}
+
+// OutputSize returns the size of the output, or of the current canvas if one
+// is active.
+func (r *Renderer) OutputSize() render.Rectangle {
+	b := r.target.Rect
+	return render.Rectangle{Width: int32(b.Dx()), Height: int32(b.Dy())}
+}
+
+// Unit returns 1/144 of the output's width or height, whichever is smaller,
+// but at least 1.
+func (r *Renderer) Unit() int32 {
+	return r.unit
+}
+
+// FillRect fills the transformed unit square with the given color.
+func (r *Renderer) FillRect(t render.Transform, color api.RGBA) {
+	c := premultiply(color)
+	r.rasterize(t, func(u, v float32) (rgba, bool) {
+		return c, true
+	})
+}
+
+// DrawImage draws the given image on the transformed unit square.
+func (r *Renderer) DrawImage(image render.Image, t render.Transform,
+	alpha uint8) {
+	r.DrawImageRegion(image, render.Rectangle{Width: image.Width,
+		Height: image.Height}, t, alpha)
+}
+
+// DrawImageRegion renders the given region of the image onto the
+// transformed unit square.
+func (r *Renderer) DrawImageRegion(image render.Image, region render.Rectangle,
+	t render.Transform, alpha uint8) {
+	r.drawRegion(image, region, t, alpha, render.DrawOptions{})
+}
+
+// DrawImageWith draws the given image on the transformed unit square,
+// applying the given options.
+func (r *Renderer) DrawImageWith(image render.Image, t render.Transform,
+	alpha uint8, opts render.DrawOptions) {
+	r.drawRegion(image, render.Rectangle{Width: image.Width,
+		Height: image.Height}, t, alpha, opts)
+}
+
+func (r *Renderer) drawRegion(image render.Image, region render.Rectangle,
+	t render.Transform, alpha uint8, opts render.DrawOptions) {
+	tex, ok := r.textures[image.TextureID]
+	if image.IsEmpty() || !ok || region.Empty() {
+		return
+	}
+	h := float32(tex.Rect.Dy())
+	f := float32(alpha) / 255
+	adjust := opts != render.DrawOptions{Blend: opts.Blend}
+	r.rasterizeWith(t, opts.Blend, func(u, v float32) (rgba, bool) {
+		x := float32(region.X) + (u+0.5)*float32(region.Width)
+		y := h - float32(region.Y) - (v+0.5)*float32(region.Height)
+		c := samplePixel(tex, x, y)
+		if adjust {
+			c = adjustColor(c, opts)
+		}
+		return c.scale(f), true
+	})
+}
+
+// FreeImage deletes the pixel data of the given image and sets it to be the
+// empty image.
+func (r *Renderer) FreeImage(i *render.Image) {
+	if i.IsEmpty() {
+		return
+	}
+	delete(r.textures, i.TextureID)
+	*i = render.EmptyImage()
+}
+
+// Clear fills the whole output with the given color, ignoring any active
+// canvas. Clear is not affected by clipping.
+func (r *Renderer) Clear(color api.RGBA) {
+	fill(r.screen, premultiply(color))
+}
+
+// Output returns a copy of the current output.
+func (r *Renderer) Output() *image.RGBA {
+	return clone(r.screen)
+}
+
+// Texture returns a copy of the pixel data of the given image, with the top
+// row first. Returns nil if the image is empty or has been freed.
+func (r *Renderer) Texture(i render.Image) *image.RGBA {
+	tex, ok := r.textures[i.TextureID]
+	if i.IsEmpty() || !ok {
+		return nil
+	}
+	return clone(tex)
+}
+
+// NumTextures returns the number of images that currently exist.
+// It can be used to detect images that are never freed.
+func (r *Renderer) NumTextures() int {
+	return len(r.textures)
+}
+
+// register stores the given texture and returns an Image referring to it.
+// All textures, including those of canvases, store the top row first and are
+// therefore never Flipped.
+func (r *Renderer) register(tex *image.RGBA, hasAlpha bool) render.Image {
+	id := r.nextID
+	r.nextID++
+	r.textures[id] = tex
+	return render.Image{TextureID: id, Width: int32(tex.Rect.Dx()),
+		Height: int32(tex.Rect.Dy()), HasAlpha: hasAlpha}
+}
+
+var _ render.Renderer = (*Renderer)(nil)
@@ -0,0 +1,136 @@
+package software
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+var (
+	red         = api.RGBA{R: 255, A: 255}
+	green       = api.RGBA{G: 255, A: 255}
+	blue        = api.RGBA{B: 255, A: 255}
+	white       = api.RGBA{R: 255, G: 255, B: 255, A: 255}
+	transparent = api.RGBA{}
+)
+
+// at returns the color at the given position of img, with y counted from
+// the bottom like in render coordinates.
+func at(img *image.RGBA, x, y int) api.RGBA {
+	c := img.RGBAAt(x, img.Rect.Dy()-1-y)
+	return api.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+func TestFillRect(t *testing.T) {
+	r := New(Options{Width: 10, Height: 10})
+	r.FillRect(render.Rectangle{X: 2, Y: 3, Width: 4, Height: 5}.Transformation(),
+		red)
+	r.FillRect(render.Rectangle{X: 4, Y: 4, Width: 1, Height: 1}.Transformation(),
+		api.RGBA{B: 255, A: 0})
+	out := r.Output()
+	tests := []struct {
+		x, y     int
+		expected api.RGBA
+	}{
+		{2, 3, red}, {5, 7, red}, {4, 4, red},
+		{1, 3, transparent}, {6, 3, transparent}, {2, 2, transparent},
+		{2, 8, transparent},
+	}
+	for _, tt := range tests {
+		if c := at(out, tt.x, tt.y); c != tt.expected {
+			t.Errorf("pixel (%d, %d) = %v, want %v", tt.x, tt.y, c, tt.expected)
+		}
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	pixels := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	pixels.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	pixels.SetRGBA(1, 0, color.RGBA{G: 255, A: 255})
+	pixels.SetRGBA(0, 1, color.RGBA{B: 255, A: 255})
+	pixels.SetRGBA(1, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	tests := []struct {
+		name   string
+		alpha  uint8
+		x, y   int
+		expect api.RGBA
+	}{
+		{"top left", 255, 0, 3, red},
+		{"top right", 255, 3, 2, green},
+		{"bottom left", 255, 1, 0, blue},
+		{"bottom right", 255, 2, 1, white},
+		{"outside", 255, 4, 0, transparent},
+		{"transparent", 0, 0, 3, transparent},
+	}
+	for _, tt := range tests {
+		r := New(Options{Width: 5, Height: 4})
+		img := r.UploadImage(render.DecodedImage{Pixels: pixels})
+		r.DrawImage(img, render.Rectangle{Width: 4, Height: 4}.Transformation(),
+			tt.alpha)
+		if c := at(r.Output(), tt.x, tt.y); c != tt.expect {
+			t.Errorf("%s: pixel (%d, %d) = %v, want %v", tt.name, tt.x, tt.y, c,
+				tt.expect)
+		}
+	}
+}
+
+func TestCanvas(t *testing.T) {
+	r := New(Options{Width: 4, Height: 4})
+	c, content := r.CreateCanvas(4, 2, api.RGB{R: 255}.AsBackground(), 0)
+	if content != (render.Rectangle{Width: 4, Height: 2}) {
+		t.Errorf("content = %v, want 4x2 at origin", content)
+	}
+	if out := r.OutputSize(); out.Width != 4 || out.Height != 2 {
+		t.Errorf("output size in canvas = %v, want 4x2", out)
+	}
+	r.FillRect(render.Rectangle{Y: 1, Width: 4, Height: 1}.Transformation(),
+		blue)
+	img := c.Finish()
+	if img.Width != 4 || img.Height != 2 || img.Flipped {
+		t.Fatalf("canvas image = %+v, want unflipped 4x2", img)
+	}
+	tex := r.Texture(img)
+	if c := at(tex, 0, 1); c != blue {
+		t.Errorf("top row of canvas = %v, want %v", c, blue)
+	}
+	if c := at(tex, 0, 0); c != red {
+		t.Errorf("bottom row of canvas = %v, want %v", c, red)
+	}
+	if c := at(r.Output(), 0, 0); c != transparent {
+		t.Errorf("canvas content was drawn to the output: %v", c)
+	}
+
+	r.DrawImage(img, render.Rectangle{Y: 1, Width: 4, Height: 2}.Transformation(),
+		255)
+	r.FillRect(render.Rectangle{Width: 4, Height: 1}.Transformation(), green)
+	out := r.Output()
+	for y, expected := range []api.RGBA{green, red, blue, transparent} {
+		if c := at(out, 2, y); c != expected {
+			t.Errorf("output row %d = %v, want %v", y, c, expected)
+		}
+	}
+	r.FreeImage(&img)
+	if r.NumTextures() != 0 {
+		t.Errorf("%d textures left after freeing the canvas image",
+			r.NumTextures())
+	}
+}
+
+func TestCanvasClose(t *testing.T) {
+	r := New(Options{Width: 4, Height: 4})
+	c, _ := r.CreateCanvas(2, 2, api.RGB{}.AsBackground(), 0)
+	c.Close()
+	r.FillRect(render.Rectangle{Width: 1, Height: 1}.Transformation(), red)
+	if c := at(r.Output(), 0, 0); c != red {
+		t.Errorf("pixel after closing canvas = %v, want %v", c, red)
+	}
+	if img := c.Finish(); !img.IsEmpty() {
+		t.Error("Finish after Close returned an image")
+	}
+	if r.NumTextures() != 0 {
+		t.Errorf("%d textures left after closing the canvas", r.NumTextures())
+	}
+}
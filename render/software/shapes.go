@@ -0,0 +1,179 @@
+package software
+
+import (
+	"math"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+)
+
+func length(x, y float32) float32 {
+	return float32(math.Hypot(float64(x), float64(y)))
+}
+
+func absFloat(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// cover composes the given color over each pixel of the current target
+// inside the given bounds, weighted by the pixel's coverage. dist returns the
+// signed distance of a pixel center to the shape's edge in pixels, which is
+// negative inside of the shape; pixels within half a pixel of the edge are
+// partially covered, which anti-aliases the edge.
+//
+// Coordinates are interpreted like OpenGL does, see rasterize.
+func (r *Renderer) cover(minX, minY, maxX, maxY float32, c rgba,
+	dist func(x, y float32) float32) {
+	w, h := r.target.Rect.Dx(), r.target.Rect.Dy()
+	x0 := clampInt(int(math.Floor(float64(minX)))-1, 0, w)
+	x1 := clampInt(int(math.Ceil(float64(maxX)))+1, 0, w)
+	y0 := clampInt(int(math.Floor(float64(minY)))-1, 0, h)
+	y1 := clampInt(int(math.Ceil(float64(maxY)))+1, 0, h)
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			coverage := 0.5 - dist(float32(x)+0.5, float32(y)+0.5)
+			if !(coverage > 0) {
+				continue
+			} else if coverage > 1 {
+				coverage = 1
+			}
+			r.compose(x, y, c.scale(coverage), render.Normal)
+		}
+	}
+}
+
+// local transforms the unit square with t and calls cover with a distance
+// func that takes coordinates relative to the square's center, scaled to
+// pixels. width and height are the square's size in pixels.
+func (r *Renderer) local(t render.Transform, c rgba,
+	dist func(x, y, width, height float32) float32) {
+	width, height := length(t[0], t[1]), length(t[2], t[3])
+	if width == 0 || height == 0 {
+		return
+	}
+	minX, minY := float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY := float32(math.Inf(-1)), float32(math.Inf(-1))
+	for _, p := range [4][2]float32{{-0.5, -0.5}, {0.5, -0.5}, {-0.5, 0.5},
+		{0.5, 0.5}} {
+		x := t[0]*p[0] + t[2]*p[1] + t[4]
+		y := t[1]*p[0] + t[3]*p[1] + t[5]
+		minX, maxX = minFloat(minX, x), maxFloat(maxX, x)
+		minY, maxY = minFloat(minY, y), maxFloat(maxY, y)
+	}
+	inv := t.Invert()
+	r.cover(minX, minY, maxX, maxY, c, func(x, y float32) float32 {
+		u := inv[0]*x + inv[2]*y + inv[4]
+		v := inv[1]*x + inv[3]*y + inv[5]
+		return dist(u*width, v*height, width, height)
+	})
+}
+
+// FillRoundedRect fills the transformed unit square with rounded corners.
+func (r *Renderer) FillRoundedRect(t render.Transform, radius float32,
+	color api.RGBA) {
+	r.local(t, premultiply(color), func(x, y, width, height float32) float32 {
+		rad := maxFloat(0, minFloat(radius, minFloat(width, height)/2))
+		qx := absFloat(x) - width/2 + rad
+		qy := absFloat(y) - height/2 + rad
+		return length(maxFloat(qx, 0), maxFloat(qy, 0)) +
+			minFloat(maxFloat(qx, qy), 0) - rad
+	})
+}
+
+// ellipseDistance approximates the signed distance of (x, y) to the ellipse
+// with the given semi-axes.
+func ellipseDistance(x, y, a, b float32) float32 {
+	k0 := length(x/a, y/b)
+	k1 := length(x/(a*a), y/(b*b))
+	if k1 == 0 {
+		return -minFloat(a, b)
+	}
+	return k0 * (k0 - 1) / k1
+}
+
+// FillEllipse fills the ellipse inscribed into the transformed unit square.
+func (r *Renderer) FillEllipse(t render.Transform, color api.RGBA) {
+	r.local(t, premultiply(color), func(x, y, width, height float32) float32 {
+		return ellipseDistance(x, y, width/2, height/2)
+	})
+}
+
+// StrokeEllipse draws the outline of the ellipse inscribed into the
+// transformed unit square.
+func (r *Renderer) StrokeEllipse(t render.Transform, width float32,
+	color api.RGBA) {
+	r.local(t, premultiply(color), func(x, y, w, h float32) float32 {
+		return absFloat(ellipseDistance(x, y, w/2, h/2)+width/2) - width/2
+	})
+}
+
+func transformPoint(t render.Transform, p render.Point) render.Point {
+	return render.Point{X: t[0]*p.X + t[2]*p.Y + t[4],
+		Y: t[1]*p.X + t[3]*p.Y + t[5]}
+}
+
+// DrawLine draws a line segment with butt ends.
+func (r *Renderer) DrawLine(t render.Transform, from, to render.Point,
+	width float32, color api.RGBA) {
+	a, b := transformPoint(t, from), transformPoint(t, to)
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := length(dx, dy)
+	if l == 0 || width <= 0 {
+		return
+	}
+	dx, dy = dx/l, dy/l
+	half := width / 2
+	r.cover(minFloat(a.X, b.X)-half, minFloat(a.Y, b.Y)-half,
+		maxFloat(a.X, b.X)+half, maxFloat(a.Y, b.Y)+half, premultiply(color),
+		func(x, y float32) float32 {
+			along := (x-a.X)*dx + (y-a.Y)*dy
+			across := absFloat((x-a.X)*dy - (y-a.Y)*dx)
+			return maxFloat(absFloat(along-l/2)-l/2, across-half)
+		})
+}
+
+// FillPolygon fills a convex polygon.
+func (r *Renderer) FillPolygon(t render.Transform, points []render.Point,
+	color api.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	p := make([]render.Point, len(points))
+	minX, minY := float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY := float32(math.Inf(-1)), float32(math.Inf(-1))
+	var area float32
+	for i := range points {
+		p[i] = transformPoint(t, points[i])
+		minX, maxX = minFloat(minX, p[i].X), maxFloat(maxX, p[i].X)
+		minY, maxY = minFloat(minY, p[i].Y), maxFloat(maxY, p[i].Y)
+	}
+	for i := range p {
+		n := p[(i+1)%len(p)]
+		area += p[i].X*n.Y - n.X*p[i].Y
+	}
+	if area == 0 {
+		return
+	}
+	// orientation makes the edge normals point outwards.
+	orientation := float32(1)
+	if area < 0 {
+		orientation = -1
+	}
+	r.cover(minX, minY, maxX, maxY, premultiply(color),
+		func(x, y float32) float32 {
+			d := float32(math.Inf(-1))
+			for i := range p {
+				n := p[(i+1)%len(p)]
+				ex, ey := n.X-p[i].X, n.Y-p[i].Y
+				l := length(ex, ey)
+				if l == 0 {
+					continue
+				}
+				d = maxFloat(d, orientation*((x-p[i].X)*ey-(y-p[i].Y)*ex)/l)
+			}
+			return d
+		})
+}
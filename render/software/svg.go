@@ -0,0 +1,54 @@
+package software
+
+import (
+	"errors"
+	"image"
+	"io/ioutil"
+	"math"
+	"net/url"
+
+	"github.com/QuestScreen/api/render"
+	"github.com/QuestScreen/api/render/svg"
+)
+
+// LoadSVGFile loads an SVG file from the given URL, which must have the file
+// scheme, and rasterizes it.
+func (r *Renderer) LoadSVGFile(path *url.URL,
+	opts render.ImageOptions) (render.Image, error) {
+	if path.Scheme != "file" {
+		return render.EmptyImage(), errors.New(
+			"unsupported URL scheme: " + path.Scheme)
+	}
+	data, err := ioutil.ReadFile(path.Path)
+	if err != nil {
+		return render.EmptyImage(), err
+	}
+	return r.LoadSVGMem(data, opts)
+}
+
+// LoadSVGMem parses an SVG document and rasterizes it. Like with
+// LoadImageMemWith, the target size is limited to MaxTextureSize. Returns an
+// error if the document's size is not a finite positive number within the
+// range of int32.
+func (r *Renderer) LoadSVGMem(data []byte,
+	opts render.ImageOptions) (render.Image, error) {
+	doc, err := svg.Parse(data)
+	if err != nil {
+		return render.EmptyImage(), err
+	}
+	w, h := doc.Size()
+	if !(w > 0 && w <= math.MaxInt32 && h > 0 && h <= math.MaxInt32) {
+		return render.EmptyImage(), errors.New(
+			"SVG size outside of supported range")
+	}
+	// the document is rendered directly at the target size, so the crop
+	// rectangle for Cover is not needed; svg.Rasterize cuts off the excess.
+	_, width, height := r.targetSize(image.Rect(0, 0,
+		int(math.Max(1, math.Ceil(w))), int(math.Max(1, math.Ceil(h)))), opts)
+	pixels, err := doc.Rasterize(int(width), int(height), opts.Fit)
+	if err != nil {
+		return render.EmptyImage(), err
+	}
+	return r.UploadImage(render.DecodedImage{Pixels: pixels,
+		HasAlpha: true}), nil
+}
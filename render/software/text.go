@@ -0,0 +1,141 @@
+package software
+
+import (
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontFamily is a font family available to the Renderer.
+type FontFamily struct {
+	Name string
+	// Faces contains the font for each api.FontStyle. If a style is nil, the
+	// RegularFont style is used instead, which must not be nil.
+	Faces [api.NumFontStyles]*opentype.Font
+}
+
+// GoFontFamily returns the Go font family, which is bundled with the
+// Renderer.
+func GoFontFamily() FontFamily {
+	return FontFamily{Name: "Go", Faces: [api.NumFontStyles]*opentype.Font{
+		mustParse(goregular.TTF), mustParse(gobold.TTF),
+		mustParse(goitalic.TTF), mustParse(gobolditalic.TTF)}}
+}
+
+func mustParse(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// fontSizes defines the em size of each api.FontSize in units.
+var fontSizes = [api.NumFontSizes]float64{3, 4, 5, 7, 9, 14}
+
+type faceKey struct {
+	family int
+	size   api.FontSize
+	style  api.FontStyle
+}
+
+type fontCache struct {
+	families []FontFamily
+	faces    map[faceKey]font.Face
+}
+
+func (fc *fontCache) init(families []FontFamily) {
+	fc.families = families
+	fc.faces = make(map[faceKey]font.Face)
+}
+
+func (fc *fontCache) face(f api.Font, unit int32) font.Face {
+	key := faceKey{family: f.FamilyIndex, size: f.Size, style: f.Style}
+	if key.family < 0 || key.family >= len(fc.families) {
+		key.family = 0
+	}
+	if key.size < 0 || key.size >= api.NumFontSizes {
+		key.size = api.ContentFont
+	}
+	if key.style < 0 || key.style >= api.NumFontStyles {
+		key.style = api.RegularFont
+	}
+	if face, ok := fc.faces[key]; ok {
+		return face
+	}
+	src := fc.families[key.family].Faces[key.style]
+	if src == nil {
+		src = fc.families[key.family].Faces[api.RegularFont]
+	}
+	face, err := opentype.NewFace(src, &opentype.FaceOptions{
+		Size: fontSizes[key.size] * float64(unit), DPI: 72,
+		Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	fc.faces[key] = face
+	return face
+}
+
+func measure(face font.Face, text string, pad padding) render.TextMetrics {
+	metrics := face.Metrics()
+	ret := render.TextMetrics{
+		Width:   int32(font.MeasureString(face, text).Ceil() + pad.left + pad.right),
+		Ascent:  int32(metrics.Ascent.Ceil() + pad.top),
+		Descent: int32(metrics.Descent.Ceil() + pad.bottom)}
+	ret.Height = ret.Ascent + ret.Descent
+	ret.Baseline = ret.Descent
+	return ret
+}
+
+// MeasureText returns the metrics of the image RenderText would create.
+// This includes the space required by the font's effects.
+func (r *Renderer) MeasureText(text string, f api.Font) render.TextMetrics {
+	return measure(r.fonts.face(f, r.unit), text, r.effects(f).padding())
+}
+
+// RenderText renders the given text into an image with transparent
+// background. The image's height is the font's ascent plus descent, extended
+// by the space required for the font's effects.
+func (r *Renderer) RenderText(text string, f api.Font) render.Image {
+	face := r.fonts.face(f, r.unit)
+	e := r.effects(f)
+	metrics := face.Metrics()
+	return r.renderRuns(font.MeasureString(face, text).Ceil(),
+		(metrics.Ascent + metrics.Descent).Ceil(), e.padding(),
+		[]textRun{{face: face, effects: e, text: text,
+			dot: fixed.P(0, metrics.Ascent.Ceil())}})
+}
+
+// RenderRichText renders the given spans on a common baseline. The image is
+// extended by the space required for the effects of all spans.
+func (r *Renderer) RenderRichText(spans []render.TextSpan) render.Image {
+	runs := make([]textRun, len(spans))
+	var width fixed.Int26_6
+	var ascent, descent int
+	var pad padding
+	for i, span := range spans {
+		face := r.fonts.face(span.Font, r.unit)
+		runs[i] = textRun{face: face, effects: r.effects(span.Font),
+			text: span.Text, dot: fixed.Point26_6{X: width}}
+		pad = pad.union(runs[i].effects.padding())
+		width += font.MeasureString(face, span.Text)
+		metrics := face.Metrics()
+		if a := metrics.Ascent.Ceil(); a > ascent {
+			ascent = a
+		}
+		if d := metrics.Descent.Ceil(); d > descent {
+			descent = d
+		}
+	}
+	for i := range runs {
+		runs[i].dot.Y = fixed.I(ascent)
+	}
+	return r.renderRuns(width.Ceil(), ascent+descent, pad, runs)
+}
@@ -0,0 +1,140 @@
+package software
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/QuestScreen/api"
+	"github.com/QuestScreen/api/render"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+type textLine struct {
+	words []string
+	width fixed.Int26_6
+	// last is true for the last line of a paragraph.
+	last bool
+}
+
+// breakWord splits a word that is wider than maxWidth into parts that fit.
+// Each part contains at least one character.
+func breakWord(face font.Face, word string,
+	maxWidth fixed.Int26_6) []string {
+	var parts []string
+	start := 0
+	for i, c := range word {
+		end := i + utf8.RuneLen(c)
+		if i > start && font.MeasureString(face, word[start:end]) > maxWidth {
+			parts = append(parts, word[start:i])
+			start = i
+		}
+	}
+	return append(parts, word[start:])
+}
+
+// wrap breaks the given text into lines no wider than maxWidth.
+func wrap(face font.Face, text string, maxWidth fixed.Int26_6) []textLine {
+	var lines []textLine
+	for _, paragraph := range strings.Split(text, "\n") {
+		cur := textLine{}
+		for _, word := range strings.Split(paragraph, " ") {
+			if word == "" {
+				continue
+			}
+			candidate := strings.Join(append(cur.words, word), " ")
+			width := font.MeasureString(face, candidate)
+			if width <= maxWidth {
+				cur.words = append(cur.words, word)
+				cur.width = width
+				continue
+			}
+			if len(cur.words) > 0 {
+				lines = append(lines, cur)
+				cur = textLine{}
+			}
+			parts := []string{word}
+			if font.MeasureString(face, word) > maxWidth {
+				parts = breakWord(face, word, maxWidth)
+			}
+			for _, part := range parts[:len(parts)-1] {
+				lines = append(lines, textLine{words: []string{part},
+					width: font.MeasureString(face, part)})
+			}
+			last := parts[len(parts)-1]
+			cur = textLine{words: []string{last},
+				width: font.MeasureString(face, last)}
+		}
+		cur.last = true
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// RenderTextBlock renders the given text into an image, breaking it into
+// lines no wider than maxWidth.
+func (r *Renderer) RenderTextBlock(text string, f api.Font, maxWidth int32,
+	align render.HAlign, lineSpacing float32) render.TextBlock {
+	if text == "" {
+		return render.TextBlock{Image: render.EmptyImage()}
+	}
+	if lineSpacing <= 0 {
+		lineSpacing = 1
+	}
+	face := r.fonts.face(f, r.unit)
+	e := r.effects(f)
+	pad := e.padding()
+	metrics := face.Metrics()
+	// lines are wrapped so that the image including the effects' padding does
+	// not exceed maxWidth.
+	textWidth := maxWidth - int32(pad.left+pad.right)
+	if textWidth < 1 {
+		textWidth = 1
+	}
+	lines := wrap(face, text, fixed.I(int(textWidth)))
+	lineHeight := int32(float32(metrics.Height.Ceil())*lineSpacing + 0.5)
+
+	width := textWidth
+	if align != render.HStretch {
+		var widest fixed.Int26_6
+		for _, l := range lines {
+			if l.width > widest {
+				widest = l.width
+			}
+		}
+		width = int32(widest.Ceil())
+	}
+	height := int32((metrics.Ascent + metrics.Descent).Ceil()) +
+		int32(len(lines)-1)*lineHeight
+	var runs []textRun
+	for i, l := range lines {
+		baseline := fixed.I(metrics.Ascent.Ceil() + i*int(lineHeight))
+		var x fixed.Int26_6
+		switch align {
+		case render.Center:
+			x = (fixed.I(int(width)) - l.width) / 2
+		case render.Right:
+			x = fixed.I(int(width)) - l.width
+		case render.HStretch:
+			if !l.last && len(l.words) > 1 {
+				var wordsWidth fixed.Int26_6
+				for _, w := range l.words {
+					wordsWidth += font.MeasureString(face, w)
+				}
+				gap := (fixed.I(int(width)) - wordsWidth) /
+					fixed.Int26_6(len(l.words)-1)
+				for _, w := range l.words {
+					runs = append(runs, textRun{face: face, effects: e, text: w,
+						dot: fixed.Point26_6{X: x, Y: baseline}})
+					x += font.MeasureString(face, w) + gap
+				}
+				continue
+			}
+		}
+		runs = append(runs, textRun{face: face, effects: e,
+			text: strings.Join(l.words, " "),
+			dot:  fixed.Point26_6{X: x, Y: baseline}})
+	}
+	return render.TextBlock{Image: r.renderRuns(int(width), int(height), pad,
+		runs), Lines: len(lines), LineHeight: lineHeight}
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SpriteSheet slices an image into a grid of equally sized frames, e.g. a set
+// of icons. Frames are indexed row by row, starting at the top left; they can
+// additionally be given names.
+type SpriteSheet struct {
+	Image Image
+	grid  Grid
+	names map[string]int
+}
+
+// NewSpriteSheet creates a sprite sheet from the given image, which must
+// consist of the given number of columns and rows of frames, separated by gap
+// pixels. The image's size should be a multiple of the frame size (with gaps),
+// otherwise the frames will differ in size by one pixel.
+//
+// names are assigned to the frames in index order; pass an empty string to
+// leave a frame unnamed. Frames without a name can still be accessed by index.
+func NewSpriteSheet(image Image, columns, rows int, gap int32,
+	names ...string) (*SpriteSheet, error) {
+	if columns <= 0 || rows <= 0 {
+		return nil, errors.New("sprite sheet needs at least one column and row")
+	}
+	if len(names) > columns*rows {
+		return nil, fmt.Errorf("%d names given for %d frames", len(names),
+			columns*rows)
+	}
+	ret := &SpriteSheet{Image: image, grid: Grid{Area: Rectangle{
+		Width: image.Width, Height: image.Height}, Columns: columns, Rows: rows,
+		Gap: gap}, names: make(map[string]int)}
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, ok := ret.names[name]; ok {
+			return nil, fmt.Errorf("duplicate frame name: %s", name)
+		}
+		ret.names[name] = i
+	}
+	return ret, nil
+}
+
+// Len returns the number of frames.
+func (s *SpriteSheet) Len() int {
+	return s.grid.Columns * s.grid.Rows
+}
+
+// Index returns the index of the frame with the given name, or -1 if no frame
+// has that name.
+func (s *SpriteSheet) Index(name string) int {
+	if index, ok := s.names[name]; ok {
+		return index
+	}
+	return -1
+}
+
+// Region returns the region of the frame with the given index in the image, as
+// expected by Image.DrawRegion.
+func (s *SpriteSheet) Region(index int) Rectangle {
+	return s.grid.Cell(index%s.grid.Columns, index/s.grid.Columns)
+}
+
+// DrawFrame draws the frame with the given index to the given area. The frame
+// will be stretched to fit the whole area. Does nothing if the index is out
+// of range.
+func (s *SpriteSheet) DrawFrame(r Renderer, index int, area Rectangle,
+	alpha uint8) {
+	if index < 0 || index >= s.Len() {
+		return
+	}
+	s.Image.DrawRegion(r, s.Region(index), area, alpha)
+}
+
+// Draw draws the frame with the given name to the given area, see DrawFrame.
+// Returns false if no frame has that name.
+func (s *SpriteSheet) Draw(r Renderer, name string, area Rectangle,
+	alpha uint8) bool {
+	index := s.Index(name)
+	if index == -1 {
+		return false
+	}
+	s.DrawFrame(r, index, area, alpha)
+	return true
+}
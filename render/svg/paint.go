@@ -0,0 +1,283 @@
+package svg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var namedColors = map[string]color.NRGBA{
+	"black":       {0, 0, 0, 255},
+	"white":       {255, 255, 255, 255},
+	"red":         {255, 0, 0, 255},
+	"green":       {0, 128, 0, 255},
+	"lime":        {0, 255, 0, 255},
+	"blue":        {0, 0, 255, 255},
+	"yellow":      {255, 255, 0, 255},
+	"cyan":        {0, 255, 255, 255},
+	"aqua":        {0, 255, 255, 255},
+	"magenta":     {255, 0, 255, 255},
+	"fuchsia":     {255, 0, 255, 255},
+	"gray":        {128, 128, 128, 255},
+	"grey":        {128, 128, 128, 255},
+	"silver":      {192, 192, 192, 255},
+	"maroon":      {128, 0, 0, 255},
+	"olive":       {128, 128, 0, 255},
+	"navy":        {0, 0, 128, 255},
+	"purple":      {128, 0, 128, 255},
+	"teal":        {0, 128, 128, 255},
+	"orange":      {255, 165, 0, 255},
+	"brown":       {165, 42, 42, 255},
+	"pink":        {255, 192, 203, 255},
+	"gold":        {255, 215, 0, 255},
+	"transparent": {0, 0, 0, 0},
+}
+
+// parseColor parses a CSS color value. ok is false if the value is not a
+// supported color.
+func parseColor(value string) (c color.NRGBA, ok bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if named, ok := namedColors[value]; ok {
+		return named, true
+	}
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return c, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return c, false
+		}
+		return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+	}
+	if strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")") {
+		parts := strings.Split(value[4:len(value)-1], ",")
+		if len(parts) != 3 {
+			return c, false
+		}
+		var channels [3]uint8
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			scale := 1.0
+			if strings.HasSuffix(part, "%") {
+				part, scale = part[:len(part)-1], 2.55
+			}
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return c, false
+			}
+			channels[i] = uint8(math.Max(0, math.Min(255, v*scale+0.5)))
+		}
+		return color.NRGBA{channels[0], channels[1], channels[2], 255}, true
+	}
+	return c, false
+}
+
+type paintKind int
+
+const (
+	noPaint paintKind = iota
+	colorPaint
+	currentColorPaint
+	gradientPaint
+)
+
+// paint is the value of a fill or stroke property.
+type paint struct {
+	kind     paintKind
+	color    color.NRGBA
+	gradient *node
+}
+
+// parsePaint parses a fill or stroke value. Unsupported values yield ok=false
+// and are ignored, so that the inherited value stays in effect.
+func (d *Document) parsePaint(value string) (p paint, ok bool) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "none":
+		return paint{kind: noPaint}, true
+	case "currentColor":
+		return paint{kind: currentColorPaint}, true
+	}
+	if strings.HasPrefix(value, "url(") {
+		end := strings.IndexByte(value, ')')
+		if end == -1 {
+			return p, false
+		}
+		id := strings.Trim(strings.TrimSpace(value[4:end]), `'"`)
+		if n, ok := d.ids[strings.TrimPrefix(id, "#")]; ok &&
+			(n.name == "linearGradient" || n.name == "radialGradient") {
+			return paint{kind: gradientPaint, gradient: n}, true
+		}
+		// use the fallback color, if any.
+		if fallback := strings.TrimSpace(value[end+1:]); fallback != "" {
+			return d.parsePaint(fallback)
+		}
+		return paint{kind: noPaint}, true
+	}
+	c, ok := parseColor(value)
+	return paint{kind: colorPaint, color: c}, ok
+}
+
+type gradientStop struct {
+	offset float64
+	color  color.NRGBA
+}
+
+// gradient is an image.Image that evaluates an SVG gradient at each pixel.
+type gradient struct {
+	radial bool
+	// coordinates of the gradient vector or center and radius, in gradient
+	// space.
+	x1, y1, x2, y2, r float64
+	// inverse maps pixel coordinates to gradient space.
+	inverse matrix
+	stops   []gradientStop
+	opacity float64
+}
+
+func (g *gradient) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (g *gradient) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (g *gradient) At(x, y int) color.Color {
+	px, py := g.inverse.apply(float64(x)+0.5, float64(y)+0.5)
+	var t float64
+	if g.radial {
+		if g.r > 0 {
+			t = math.Hypot(px-g.x1, py-g.y1) / g.r
+		}
+	} else {
+		dx, dy := g.x2-g.x1, g.y2-g.y1
+		if l := dx*dx + dy*dy; l > 0 {
+			t = ((px-g.x1)*dx + (py-g.y1)*dy) / l
+		}
+	}
+	if len(g.stops) == 0 {
+		return color.RGBA{}
+	}
+	c := g.stops[len(g.stops)-1].color
+	for i, stop := range g.stops {
+		if t <= stop.offset {
+			c = stop.color
+			if i > 0 {
+				prev := g.stops[i-1]
+				f := 0.0
+				if span := stop.offset - prev.offset; span > 0 {
+					f = (t - prev.offset) / span
+				}
+				c = lerpColor(prev.color, stop.color, f)
+			}
+			break
+		}
+	}
+	a := float64(c.A) * g.opacity / 255
+	return color.RGBA{uint8(float64(c.R)*a + 0.5), uint8(float64(c.G)*a + 0.5),
+		uint8(float64(c.B)*a + 0.5), uint8(255*a + 0.5)}
+}
+
+func lerpColor(a, b color.NRGBA, f float64) color.NRGBA {
+	l := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*f + 0.5)
+	}
+	return color.NRGBA{l(a.R, b.R), l(a.G, b.G), l(a.B, b.B), l(a.A, b.A)}
+}
+
+// gradientStops returns the stops of the given gradient element. If it has
+// none, the stops of the gradient it references are used.
+func (d *Document) gradientStops(n *node, depth int) []gradientStop {
+	var ret []gradientStop
+	for _, c := range n.children {
+		if c.name != "stop" {
+			continue
+		}
+		props := c.properties()
+		stop := gradientStop{color: color.NRGBA{A: 255}}
+		if v, ok := props["stop-color"]; ok {
+			if col, ok := parseColor(v); ok {
+				stop.color = col
+			}
+		}
+		if v, ok := props["stop-opacity"]; ok {
+			stop.color.A = uint8(float64(stop.color.A)*clamp01(parseFraction(v)) +
+				0.5)
+		}
+		stop.offset = clamp01(parseFraction(c.attr("offset", "0")))
+		// offsets must not decrease.
+		if len(ret) > 0 && stop.offset < ret[len(ret)-1].offset {
+			stop.offset = ret[len(ret)-1].offset
+		}
+		ret = append(ret, stop)
+	}
+	if len(ret) == 0 && depth < 8 {
+		if ref, ok := d.ids[strings.TrimPrefix(n.href(), "#")]; ok {
+			return d.gradientStops(ref, depth+1)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].offset < ret[j].offset
+	})
+	return ret
+}
+
+// gradientImage creates the image that fills a shape with the given gradient.
+// bbox is the shape's bounding box in user space, m the transformation from
+// user space to pixels.
+func (d *Document) gradientImage(n *node, bbox [4]float64, m matrix,
+	opacity float64) image.Image {
+	g := &gradient{radial: n.name == "radialGradient",
+		stops: d.gradientStops(n, 0), opacity: opacity}
+	userSpace := n.attrs["gradientUnits"] == "userSpaceOnUse"
+	w, h := 1.0, 1.0
+	if userSpace {
+		w, h = d.viewBox[2], d.viewBox[3]
+	}
+	if g.radial {
+		g.x1 = parseLength(n.attr("cx", "50%"), w)
+		g.y1 = parseLength(n.attr("cy", "50%"), h)
+		g.r = parseLength(n.attr("r", "50%"), math.Hypot(w, h)/math.Sqrt2)
+	} else {
+		g.x1 = parseLength(n.attr("x1", "0%"), w)
+		g.y1 = parseLength(n.attr("y1", "0%"), h)
+		g.x2 = parseLength(n.attr("x2", "100%"), w)
+		g.y2 = parseLength(n.attr("y2", "0%"), h)
+	}
+	if !userSpace {
+		m = m.mul(matrix{bbox[2] - bbox[0], 0, 0, bbox[3] - bbox[1], bbox[0],
+			bbox[1]})
+	}
+	if t, ok := n.attrs["gradientTransform"]; ok {
+		m = m.mul(parseTransform(t))
+	}
+	g.inverse = m.invert()
+	return g
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// parseFraction parses a number or percentage into a fraction.
+func parseFraction(value string) float64 {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		v, _ := strconv.ParseFloat(value[:len(value)-1], 64)
+		return v / 100
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 1
+	}
+	return v
+}
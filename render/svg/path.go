@@ -0,0 +1,348 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+type point struct {
+	x, y float64
+}
+
+// segment is a part of a path in absolute coordinates. Arcs, quadratic curves
+// and shorthand commands are converted to moveTo, lineTo, cubicTo and close.
+type segment struct {
+	kind byte
+	// pts holds one point for moveTo and lineTo, and the two control points
+	// followed by the end point for cubicTo.
+	pts [3]point
+}
+
+const (
+	moveTo byte = iota
+	lineTo
+	cubicTo
+	closePath
+)
+
+type path []segment
+
+func (p *path) moveTo(x, y float64) {
+	*p = append(*p, segment{kind: moveTo, pts: [3]point{{x, y}}})
+}
+
+func (p *path) lineTo(x, y float64) {
+	*p = append(*p, segment{kind: lineTo, pts: [3]point{{x, y}}})
+}
+
+func (p *path) cubicTo(x1, y1, x2, y2, x, y float64) {
+	*p = append(*p, segment{kind: cubicTo,
+		pts: [3]point{{x1, y1}, {x2, y2}, {x, y}}})
+}
+
+func (p *path) close() {
+	*p = append(*p, segment{kind: closePath})
+}
+
+// arcTo appends an elliptical arc from (x0, y0) as defined by the SVG path
+// command A, approximated by cubic curves.
+func (p *path) arcTo(x0, y0, rx, ry, rotation float64, large, sweep bool,
+	x, y float64) {
+	if rx == 0 || ry == 0 || (x0 == x && y0 == y) {
+		p.lineTo(x, y)
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	phi := rotation * math.Pi / 180
+	sin, cos := math.Sincos(phi)
+	// conversion from endpoint to center parameterization, see the
+	// implementation notes of the SVG specification.
+	dx, dy := (x0-x)/2, (y0-y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	f := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		f = -f
+	}
+	cx1, cy1 := f*rx*y1/ry, -f*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (x0+x)/2
+	cy := sin*cx1 + cos*cy1 + (y0+y)/2
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	start := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	ellipse := func(t float64) (px, py, tx, ty float64) {
+		st, ct := math.Sincos(t)
+		ex, ey := rx*ct, ry*st
+		// derivative, used for the control points
+		dx, dy := -rx*st, ry*ct
+		return cos*ex - sin*ey + cx, sin*ex + cos*ey + cy,
+			cos*dx - sin*dy, sin*dx + cos*dy
+	}
+	t := start
+	px, py, tx, ty := ellipse(t)
+	for i := 0; i < n; i++ {
+		qx, qy, ux, uy := ellipse(t + step)
+		if i == n-1 {
+			qx, qy = x, y
+		}
+		p.cubicTo(px+k*tx, py+k*ty, qx-k*ux, qy-k*uy, qx, qy)
+		t += step
+		px, py, tx, ty = qx, qy, ux, uy
+	}
+}
+
+// ellipse appends a closed ellipse with the given center and radii.
+func (p *path) ellipse(cx, cy, rx, ry float64) {
+	p.moveTo(cx+rx, cy)
+	p.arcTo(cx+rx, cy, rx, ry, 0, false, true, cx-rx, cy)
+	p.arcTo(cx-rx, cy, rx, ry, 0, false, true, cx+rx, cy)
+	p.close()
+}
+
+// scanner reads the numbers and flags of path data and point lists.
+type scanner struct {
+	s string
+	i int
+}
+
+func (s *scanner) skipSeparators() {
+	for s.i < len(s.s) {
+		switch s.s[s.i] {
+		case ' ', '\t', '\n', '\r', ',':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+func (s *scanner) done() bool {
+	s.skipSeparators()
+	return s.i >= len(s.s)
+}
+
+func (s *scanner) atNumber() bool {
+	s.skipSeparators()
+	if s.i >= len(s.s) {
+		return false
+	}
+	c := s.s[s.i]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+func (s *scanner) number() (float64, error) {
+	s.skipSeparators()
+	start := s.i
+	if s.i < len(s.s) && (s.s[s.i] == '-' || s.s[s.i] == '+') {
+		s.i++
+	}
+	dot, exp := false, false
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && !dot && !exp:
+			dot = true
+		case (c == 'e' || c == 'E') && !exp && s.i > start:
+			exp = true
+			if s.i+1 < len(s.s) && (s.s[s.i+1] == '-' || s.s[s.i+1] == '+') {
+				s.i++
+			}
+		default:
+			goto end
+		}
+		s.i++
+	}
+end:
+	v, err := strconv.ParseFloat(s.s[start:s.i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number at position %d", start)
+	}
+	return v, nil
+}
+
+func (s *scanner) flag() (bool, error) {
+	s.skipSeparators()
+	if s.i < len(s.s) {
+		switch s.s[s.i] {
+		case '0':
+			s.i++
+			return false, nil
+		case '1':
+			s.i++
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("invalid flag at position %d", s.i)
+}
+
+func (s *scanner) numbers(target ...*float64) error {
+	for _, t := range target {
+		v, err := s.number()
+		if err != nil {
+			return err
+		}
+		*t = v
+	}
+	return nil
+}
+
+// parsePoints parses the points attribute of polyline and polygon.
+func parsePoints(data string) []point {
+	s := scanner{s: data}
+	var ret []point
+	for s.atNumber() {
+		var p point
+		if s.numbers(&p.x, &p.y) != nil {
+			break
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// parsePath parses SVG path data. On error, the path up to the error is
+// returned, as the SVG specification demands.
+func parsePath(data string) (path, error) {
+	var ret path
+	s := scanner{s: data}
+	var cmd byte
+	// current point, start of the current subpath, and the last control point
+	// for the shorthand curve commands.
+	var cx, cy, sx, sy, lx, ly float64
+	var last byte
+	for !s.done() {
+		if c := s.s[s.i]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			cmd = c
+			s.i++
+		} else if cmd == 0 {
+			return ret, fmt.Errorf("path data must start with a command")
+		}
+		rel := cmd >= 'a'
+		var ox, oy float64
+		if rel {
+			ox, oy = cx, cy
+		}
+		var err error
+		switch cmd {
+		case 'M', 'm':
+			var x, y float64
+			if err = s.numbers(&x, &y); err != nil {
+				break
+			}
+			cx, cy = ox+x, oy+y
+			sx, sy = cx, cy
+			ret.moveTo(cx, cy)
+			// further coordinate pairs are implicit lineTo commands.
+			cmd = 'L' + cmd - 'M'
+		case 'L', 'l':
+			var x, y float64
+			if err = s.numbers(&x, &y); err != nil {
+				break
+			}
+			cx, cy = ox+x, oy+y
+			ret.lineTo(cx, cy)
+		case 'H', 'h':
+			var x float64
+			if err = s.numbers(&x); err != nil {
+				break
+			}
+			cx = ox + x
+			ret.lineTo(cx, cy)
+		case 'V', 'v':
+			var y float64
+			if err = s.numbers(&y); err != nil {
+				break
+			}
+			cy = oy + y
+			ret.lineTo(cx, cy)
+		case 'C', 'c':
+			var x1, y1, x2, y2, x, y float64
+			if err = s.numbers(&x1, &y1, &x2, &y2, &x, &y); err != nil {
+				break
+			}
+			lx, ly = ox+x2, oy+y2
+			cx, cy = ox+x, oy+y
+			ret.cubicTo(ox+x1, oy+y1, lx, ly, cx, cy)
+		case 'S', 's':
+			var x2, y2, x, y float64
+			if err = s.numbers(&x2, &y2, &x, &y); err != nil {
+				break
+			}
+			x1, y1 := cx, cy
+			if last == 'C' || last == 'S' {
+				x1, y1 = 2*cx-lx, 2*cy-ly
+			}
+			lx, ly = ox+x2, oy+y2
+			cx, cy = ox+x, oy+y
+			ret.cubicTo(x1, y1, lx, ly, cx, cy)
+		case 'Q', 'q', 'T', 't':
+			var qx, qy, x, y float64
+			if cmd == 'Q' || cmd == 'q' {
+				if err = s.numbers(&qx, &qy, &x, &y); err != nil {
+					break
+				}
+				qx, qy = ox+qx, oy+qy
+			} else {
+				if err = s.numbers(&x, &y); err != nil {
+					break
+				}
+				qx, qy = cx, cy
+				if last == 'Q' || last == 'T' {
+					qx, qy = 2*cx-lx, 2*cy-ly
+				}
+			}
+			x, y = ox+x, oy+y
+			// elevate the quadratic curve to a cubic one.
+			ret.cubicTo(cx+2.0/3*(qx-cx), cy+2.0/3*(qy-cy),
+				x+2.0/3*(qx-x), y+2.0/3*(qy-y), x, y)
+			lx, ly = qx, qy
+			cx, cy = x, y
+		case 'A', 'a':
+			var rx, ry, rotation, x, y float64
+			var large, sweep bool
+			if err = s.numbers(&rx, &ry, &rotation); err != nil {
+				break
+			}
+			if large, err = s.flag(); err != nil {
+				break
+			}
+			if sweep, err = s.flag(); err != nil {
+				break
+			}
+			if err = s.numbers(&x, &y); err != nil {
+				break
+			}
+			ret.arcTo(cx, cy, rx, ry, rotation, large, sweep, ox+x, oy+y)
+			cx, cy = ox+x, oy+y
+		case 'Z', 'z':
+			ret.close()
+			cx, cy = sx, sy
+			last, cmd = 'Z', 0
+			continue
+		default:
+			return ret, fmt.Errorf("unknown path command: %c", cmd)
+		}
+		if err != nil {
+			return ret, err
+		}
+		last = cmd &^ 0x20 // upper case
+	}
+	return ret, nil
+}
@@ -0,0 +1,212 @@
+package svg
+
+import "math"
+
+// miterLimit is the default value of SVG's stroke-miterlimit.
+const miterLimit = 4
+
+// polyline is a flattened subpath in pixel coordinates.
+type polyline struct {
+	pts    []point
+	closed bool
+}
+
+// flatten transforms the path with m and approximates its curves by lines.
+func (p path) flatten(m matrix) []polyline {
+	var ret []polyline
+	var cur *polyline
+	add := func(x, y float64) {
+		pt := point{x, y}
+		if n := len(cur.pts); n > 0 && cur.pts[n-1] == pt {
+			return
+		}
+		cur.pts = append(cur.pts, pt)
+	}
+	var start, last point
+	for _, seg := range p {
+		if cur == nil && seg.kind != moveTo {
+			// implicit subpath after a close.
+			ret = append(ret, polyline{})
+			cur = &ret[len(ret)-1]
+			add(m.apply(start.x, start.y))
+		}
+		switch seg.kind {
+		case moveTo:
+			start = seg.pts[0]
+			ret = append(ret, polyline{})
+			cur = &ret[len(ret)-1]
+			add(m.apply(start.x, start.y))
+		case lineTo:
+			add(m.apply(seg.pts[0].x, seg.pts[0].y))
+		case cubicTo:
+			var q [4]point
+			q[0].x, q[0].y = m.apply(last.x, last.y)
+			for i := 0; i < 3; i++ {
+				q[i+1].x, q[i+1].y = m.apply(seg.pts[i].x, seg.pts[i].y)
+			}
+			length := math.Hypot(q[1].x-q[0].x, q[1].y-q[0].y) +
+				math.Hypot(q[2].x-q[1].x, q[2].y-q[1].y) +
+				math.Hypot(q[3].x-q[2].x, q[3].y-q[2].y)
+			n := int(math.Ceil(length / 3))
+			if n < 1 {
+				n = 1
+			} else if n > 64 {
+				n = 64
+			}
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+				add(a*q[0].x+b*q[1].x+c*q[2].x+d*q[3].x,
+					a*q[0].y+b*q[1].y+c*q[2].y+d*q[3].y)
+			}
+		case closePath:
+			cur.closed = true
+			cur = nil
+			last = start
+			continue
+		}
+		last = seg.pts[0]
+		if seg.kind == cubicTo {
+			last = seg.pts[2]
+		}
+	}
+	return ret
+}
+
+// polygon adds a closed polygon to the rasterizer. All polygons are added
+// with the same orientation so that overlapping parts of a stroke do not
+// cancel each other out.
+func (r *rasterizer) polygon(pts ...point) {
+	area := 0.0
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		area += p.x*q.y - q.x*p.y
+	}
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	r.z.MoveTo(float32(pts[0].x), float32(pts[0].y))
+	for _, p := range pts[1:] {
+		r.z.LineTo(float32(p.x), float32(p.y))
+	}
+	r.z.ClosePath()
+}
+
+func (r *rasterizer) circle(c point, radius float64) {
+	n := int(math.Ceil(radius * 2))
+	if n < 8 {
+		n = 8
+	} else if n > 64 {
+		n = 64
+	}
+	pts := make([]point, n)
+	for i := range pts {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		pts[i] = point{c.x + cos*radius, c.y + sin*radius}
+	}
+	r.polygon(pts...)
+}
+
+// normal returns the vector of length hw perpendicular to the line from a to
+// b.
+func normal(a, b point, hw float64) point {
+	l := math.Hypot(b.x-a.x, b.y-a.y)
+	return point{-(b.y - a.y) / l * hw, (b.x - a.x) / l * hw}
+}
+
+func (r *rasterizer) join(v, prev, next point, hw float64, s style) {
+	if s.lineJoin == "round" {
+		r.circle(v, hw)
+		return
+	}
+	n1, n2 := normal(prev, v, hw), normal(v, next, hw)
+	// the outer side of the join is the one the path turns away from.
+	if (next.x-v.x)*n1.x+(next.y-v.y)*n1.y > 0 {
+		n1, n2 = point{-n1.x, -n1.y}, point{-n2.x, -n2.y}
+	}
+	a, b := point{v.x + n1.x, v.y + n1.y}, point{v.x + n2.x, v.y + n2.y}
+	if s.lineJoin != "bevel" {
+		bx, by := n1.x+n2.x, n1.y+n2.y
+		if l := math.Hypot(bx, by); l > 0 {
+			// cosine of half the angle between the segments.
+			cos := (bx*n1.x + by*n1.y) / (l * hw)
+			if cos > 0 && 1/cos <= miterLimit {
+				d := hw / cos / l
+				r.polygon(v, a, point{v.x + bx*d, v.y + by*d}, b)
+				return
+			}
+		}
+	}
+	r.polygon(v, a, b)
+}
+
+func (r *rasterizer) cap(p, from point, hw float64, s style) {
+	switch s.lineCap {
+	case "round":
+		r.circle(p, hw)
+	case "square":
+		n := normal(from, p, hw)
+		// direction of the line, scaled to hw.
+		d := point{n.y, -n.x}
+		r.polygon(point{p.x + n.x, p.y + n.y},
+			point{p.x + n.x + d.x, p.y + n.y + d.y},
+			point{p.x - n.x + d.x, p.y - n.y + d.y},
+			point{p.x - n.x, p.y - n.y})
+	}
+}
+
+func (r *rasterizer) stroke(p path, m matrix, s style) {
+	hw := s.strokeWidth * m.scale() / 2
+	if hw <= 0 {
+		return
+	}
+	src := r.source(s.stroke, s.strokeOpacity, s, p, m)
+	if src == nil || !r.check(hw) {
+		return
+	}
+	lines := p.flatten(m)
+	for _, line := range lines {
+		for _, pt := range line.pts {
+			if !r.check(pt.x, pt.y) {
+				return
+			}
+		}
+	}
+	for _, line := range lines {
+		pts := line.pts
+		if len(pts) < 2 {
+			if len(pts) == 1 && s.lineCap == "round" {
+				r.circle(pts[0], hw)
+			}
+			continue
+		}
+		if line.closed && pts[0] == pts[len(pts)-1] {
+			pts = pts[:len(pts)-1]
+		}
+		n := len(pts)
+		segments := n - 1
+		if line.closed {
+			segments = n
+		}
+		for i := 0; i < segments; i++ {
+			a, b := pts[i], pts[(i+1)%n]
+			d := normal(a, b, hw)
+			r.polygon(point{a.x + d.x, a.y + d.y}, point{b.x + d.x, b.y + d.y},
+				point{b.x - d.x, b.y - d.y}, point{a.x - d.x, a.y - d.y})
+		}
+		for i := 0; i < n; i++ {
+			if !line.closed && (i == 0 || i == n-1) {
+				continue
+			}
+			r.join(pts[i], pts[(i+n-1)%n], pts[(i+1)%n], hw, s)
+		}
+		if !line.closed {
+			r.cap(pts[0], pts[1], hw, s)
+			r.cap(pts[n-1], pts[n-2], hw, s)
+		}
+	}
+	r.draw(src)
+}
@@ -0,0 +1,645 @@
+// Package svg rasterizes SVG documents into images.
+//
+// It implements the subset of SVG commonly found in icons and logos: the
+// elements svg, g, use, symbol, path, rect, circle, ellipse, line, polyline
+// and polygon; fill and stroke with colors as well as linear and radial
+// gradients; and the transform, opacity and style attributes. Text, filters,
+// masks, clipping paths, dashes, CSS style sheets and the evenodd fill rule
+// are not supported. Group opacity is applied to each child separately.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/QuestScreen/api/render"
+	"golang.org/x/image/vector"
+)
+
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+}
+
+func (n *node) attr(name, fallback string) string {
+	if v, ok := n.attrs[name]; ok {
+		return v
+	}
+	return fallback
+}
+
+func (n *node) href() string {
+	return n.attrs["href"]
+}
+
+// properties returns the node's presentation attributes, overridden by the
+// declarations of its style attribute.
+func (n *node) properties() map[string]string {
+	style, ok := n.attrs["style"]
+	if !ok {
+		return n.attrs
+	}
+	ret := make(map[string]string, len(n.attrs))
+	for k, v := range n.attrs {
+		ret[k] = v
+	}
+	for _, decl := range strings.Split(style, ";") {
+		if colon := strings.IndexByte(decl, ':'); colon != -1 {
+			ret[strings.TrimSpace(decl[:colon])] = strings.TrimSpace(
+				decl[colon+1:])
+		}
+	}
+	return ret
+}
+
+// Document is a parsed SVG document.
+type Document struct {
+	root *node
+	ids  map[string]*node
+	// viewBox is x, y, width and height of the user space shown.
+	viewBox       [4]float64
+	width, height float64
+}
+
+// Parse parses an SVG document.
+func Parse(data []byte) (*Document, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	// SVG files are usually UTF-8 but may declare other ASCII-compatible
+	// encodings.
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader,
+		error) {
+		return input, nil
+	}
+	d := &Document{ids: make(map[string]*node)}
+	var stack []*node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if id, ok := n.attrs["id"]; ok {
+				d.ids[id] = n
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if d.root == nil {
+				d.root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if d.root == nil || d.root.name != "svg" {
+		return nil, errors.New("not an SVG document")
+	}
+	d.width = parseLength(d.root.attr("width", ""), 0)
+	d.height = parseLength(d.root.attr("height", ""), 0)
+	if vb := parsePoints(d.root.attr("viewBox", "")); len(vb) == 2 &&
+		vb[1].x > 0 && vb[1].y > 0 {
+		d.viewBox = [4]float64{vb[0].x, vb[0].y, vb[1].x, vb[1].y}
+		switch {
+		case d.width <= 0 && d.height <= 0:
+			d.width, d.height = vb[1].x, vb[1].y
+		case d.width <= 0:
+			d.width = d.height * vb[1].x / vb[1].y
+		case d.height <= 0:
+			d.height = d.width * vb[1].y / vb[1].x
+		}
+	} else {
+		// default size of replaced elements in CSS.
+		if d.width <= 0 {
+			d.width = 300
+		}
+		if d.height <= 0 {
+			d.height = 150
+		}
+		d.viewBox = [4]float64{0, 0, d.width, d.height}
+	}
+	return d, nil
+}
+
+// Size returns the intrinsic size of the document in pixels, as defined by
+// its width, height and viewBox attributes.
+func (d *Document) Size() (width, height float64) {
+	return d.width, d.height
+}
+
+// Rasterize renders the document into an image of the given size. fit
+// defines how the viewBox is scaled into the image if the aspect ratios
+// differ; it is centered both horizontally and vertically. A
+// preserveAspectRatio of none in the document overrides fit with
+// render.Exact.
+//
+// Returns an error if a shape has coordinates that are too large to be
+// rasterized.
+func (d *Document) Rasterize(width, height int,
+	fit render.FitMode) (*image.RGBA, error) {
+	ret := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width <= 0 || height <= 0 {
+		return ret, nil
+	}
+	if ignoresAspectRatio(d.root) {
+		fit = render.Exact
+	}
+	r := &rasterizer{doc: d, target: ret,
+		z:      vector.NewRasterizer(width, height),
+		active: map[*node]bool{d.root: true}}
+	r.children(d.root, viewBoxMatrix(d.viewBox, float64(width),
+		float64(height), fit), defaultStyle(), 0)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return ret, nil
+}
+
+// ignoresAspectRatio returns true iff the preserveAspectRatio attribute of
+// the given node is none.
+func ignoresAspectRatio(n *node) bool {
+	return strings.HasPrefix(strings.TrimSpace(
+		n.attr("preserveAspectRatio", "")), "none")
+}
+
+// viewBoxMatrix returns the transformation that maps the given viewBox into
+// a viewport of the given size at the origin, centered according to fit.
+func viewBoxMatrix(vb [4]float64, width, height float64,
+	fit render.FitMode) matrix {
+	sx, sy := width/vb[2], height/vb[3]
+	switch fit {
+	case render.Contain:
+		sx = math.Min(sx, sy)
+		sy = sx
+	case render.Cover:
+		sx = math.Max(sx, sy)
+		sy = sx
+	}
+	return matrix{sx, 0, 0, sy, (width-vb[2]*sx)/2 - vb[0]*sx,
+		(height-vb[3]*sy)/2 - vb[1]*sy}
+}
+
+// maxDepth limits the nesting of use elements to prevent endless recursion.
+const maxDepth = 32
+
+// maxElements limits the number of elements visited while rendering, since
+// use elements can reference the same content many times.
+const maxElements = 1 << 16
+
+type style struct {
+	fill, stroke                        paint
+	fillOpacity, strokeOpacity, opacity float64
+	strokeWidth                         float64
+	lineCap, lineJoin                   string
+	color                               color.NRGBA
+}
+
+func defaultStyle() style {
+	return style{fill: paint{kind: colorPaint, color: color.NRGBA{A: 255}},
+		fillOpacity: 1, strokeOpacity: 1, opacity: 1, strokeWidth: 1,
+		lineCap: "butt", lineJoin: "miter", color: color.NRGBA{A: 255}}
+}
+
+// apply returns the style of the given node, inheriting from s. ok is false
+// if the node is not displayed.
+func (d *Document) apply(s style, n *node) (ret style, ok bool) {
+	props := n.properties()
+	if props["display"] == "none" || props["visibility"] == "hidden" {
+		return s, false
+	}
+	if v, ok := props["color"]; ok {
+		if c, ok := parseColor(v); ok {
+			s.color = c
+		}
+	}
+	if v, ok := props["fill"]; ok {
+		if p, ok := d.parsePaint(v); ok {
+			s.fill = p
+		}
+	}
+	if v, ok := props["stroke"]; ok {
+		if p, ok := d.parsePaint(v); ok {
+			s.stroke = p
+		}
+	}
+	if v, ok := props["fill-opacity"]; ok {
+		s.fillOpacity = clamp01(parseFraction(v))
+	}
+	if v, ok := props["stroke-opacity"]; ok {
+		s.strokeOpacity = clamp01(parseFraction(v))
+	}
+	if v, ok := props["opacity"]; ok {
+		s.opacity *= clamp01(parseFraction(v))
+	}
+	if v, ok := props["stroke-width"]; ok {
+		s.strokeWidth = parseLength(v, math.Hypot(d.viewBox[2],
+			d.viewBox[3])/math.Sqrt2)
+	}
+	if v, ok := props["stroke-linecap"]; ok {
+		s.lineCap = v
+	}
+	if v, ok := props["stroke-linejoin"]; ok {
+		s.lineJoin = v
+	}
+	return s, true
+}
+
+// matrix is an affine transformation (a, b, c, d, e, f) as defined by SVG.
+type matrix [6]float64
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// mul returns the transformation that applies o first, then m.
+func (m matrix) mul(o matrix) matrix {
+	return matrix{m[0]*o[0] + m[2]*o[1], m[1]*o[0] + m[3]*o[1],
+		m[0]*o[2] + m[2]*o[3], m[1]*o[2] + m[3]*o[3],
+		m[0]*o[4] + m[2]*o[5] + m[4], m[1]*o[4] + m[3]*o[5] + m[5]}
+}
+
+func (m matrix) invert() matrix {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return matrix{}
+	}
+	return matrix{m[3] / det, -m[1] / det, -m[2] / det, m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det, (m[1]*m[4] - m[0]*m[5]) / det}
+}
+
+// scale returns the average factor by which m scales lengths.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// parseTransform parses the value of a transform attribute. Parsing stops at
+// the first invalid transformation.
+func parseTransform(value string) matrix {
+	ret := identity
+	for {
+		value = strings.TrimLeft(value, " \t\r\n,")
+		open := strings.IndexByte(value, '(')
+		end := strings.IndexByte(value, ')')
+		if open == -1 || end < open {
+			return ret
+		}
+		name := strings.TrimSpace(value[:open])
+		args := parsePointsFlat(value[open+1 : end])
+		value = value[end+1:]
+		var t matrix
+		switch {
+		case name == "matrix" && len(args) == 6:
+			copy(t[:], args)
+		case name == "translate" && len(args) == 1:
+			t = matrix{1, 0, 0, 1, args[0], 0}
+		case name == "translate" && len(args) == 2:
+			t = matrix{1, 0, 0, 1, args[0], args[1]}
+		case name == "scale" && len(args) == 1:
+			t = matrix{args[0], 0, 0, args[0], 0, 0}
+		case name == "scale" && len(args) == 2:
+			t = matrix{args[0], 0, 0, args[1], 0, 0}
+		case name == "rotate" && (len(args) == 1 || len(args) == 3):
+			sin, cos := math.Sincos(args[0] * math.Pi / 180)
+			t = matrix{cos, sin, -sin, cos, 0, 0}
+			if len(args) == 3 {
+				t = matrix{1, 0, 0, 1, args[1], args[2]}.mul(t).mul(
+					matrix{1, 0, 0, 1, -args[1], -args[2]})
+			}
+		case name == "skewX" && len(args) == 1:
+			t = matrix{1, 0, math.Tan(args[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(args) == 1:
+			t = matrix{1, math.Tan(args[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return ret
+		}
+		ret = ret.mul(t)
+	}
+}
+
+func parsePointsFlat(data string) []float64 {
+	s := scanner{s: data}
+	var ret []float64
+	for s.atNumber() {
+		v, err := s.number()
+		if err != nil {
+			break
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// unitFactors converts absolute CSS units to pixels.
+var unitFactors = map[string]float64{
+	"px": 1, "pt": 96.0 / 72, "pc": 16, "mm": 96 / 25.4, "cm": 96 / 2.54,
+	"in": 96,
+}
+
+// parseLength parses a length. Percentages are relative to the given
+// reference length. Invalid lengths yield 0.
+func parseLength(value string, reference float64) float64 {
+	value = strings.TrimSpace(value)
+	factor := 1.0
+	if strings.HasSuffix(value, "%") {
+		value, factor = value[:len(value)-1], reference/100
+	} else if len(value) > 2 {
+		if f, ok := unitFactors[value[len(value)-2:]]; ok {
+			value, factor = value[:len(value)-2], f
+		}
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return v * factor
+}
+
+type rasterizer struct {
+	doc    *Document
+	target *image.RGBA
+	z      *vector.Rasterizer
+	// err is set when a shape cannot be rasterized; rendering stops then.
+	err error
+	// active contains the elements currently being rendered. use elements
+	// referencing them are circular and skipped, as browsers do.
+	active map[*node]bool
+	// visited is the number of elements rendered so far.
+	visited int
+}
+
+// maxCoordinate is the largest absolute device coordinate of a shape. The
+// fixed-point math of the vector rasterizer overflows for larger values,
+// which must also leave room for miter joins of strokes.
+const maxCoordinate = 1 << 19
+
+// check sets r.err and returns false if any of the given values is not a
+// finite number within [-maxCoordinate..maxCoordinate].
+func (r *rasterizer) check(values ...float64) bool {
+	for _, v := range values {
+		if !(v >= -maxCoordinate && v <= maxCoordinate) {
+			r.err = errors.New("shape coordinates outside of supported range")
+			return false
+		}
+	}
+	return true
+}
+
+func (r *rasterizer) children(n *node, m matrix, s style, depth int) {
+	for _, c := range n.children {
+		if r.err != nil {
+			return
+		}
+		r.element(c, m, s, depth)
+	}
+}
+
+// symbol renders the children of a symbol element referenced by the given
+// use element into the viewport defined by the use element's width and
+// height, which default to the symbol's width and height, or 100%.
+func (r *rasterizer) symbol(n, use *node, m matrix, s style, depth int) {
+	s, ok := r.doc.apply(s, n)
+	if !ok {
+		return
+	}
+	vb := r.doc.viewBox
+	width := parseLength(use.attr("width", n.attr("width", "100%")), vb[2])
+	height := parseLength(use.attr("height", n.attr("height", "100%")), vb[3])
+	if !(width > 0 && height > 0) {
+		return
+	}
+	if own := parsePoints(n.attr("viewBox", "")); len(own) == 2 &&
+		own[1].x > 0 && own[1].y > 0 {
+		fit := render.Contain
+		if ignoresAspectRatio(n) {
+			fit = render.Exact
+		}
+		m = m.mul(viewBoxMatrix([4]float64{own[0].x, own[0].y, own[1].x,
+			own[1].y}, width, height, fit))
+	}
+	r.active[n] = true
+	r.children(n, m, s, depth)
+	delete(r.active, n)
+}
+
+func (r *rasterizer) element(n *node, m matrix, s style, depth int) {
+	r.visited++
+	if r.visited > maxElements {
+		r.err = errors.New("too many elements to render")
+		return
+	}
+	s, ok := r.doc.apply(s, n)
+	if !ok {
+		return
+	}
+	if t, ok := n.attrs["transform"]; ok {
+		m = m.mul(parseTransform(t))
+	}
+	vb := r.doc.viewBox
+	diag := math.Hypot(vb[2], vb[3]) / math.Sqrt2
+	length := func(name string, reference float64) float64 {
+		return parseLength(n.attr(name, "0"), reference)
+	}
+	var p path
+	switch n.name {
+	case "g", "svg", "a":
+		r.active[n] = true
+		r.children(n, m, s, depth)
+		delete(r.active, n)
+		return
+	case "use":
+		ref, ok := r.doc.ids[strings.TrimPrefix(n.href(), "#")]
+		if ok && !r.active[ref] && depth < maxDepth {
+			m = m.mul(matrix{1, 0, 0, 1, length("x", vb[2]), length("y", vb[3])})
+			if ref.name == "symbol" {
+				r.symbol(ref, n, m, s, depth+1)
+			} else {
+				r.element(ref, m, s, depth+1)
+			}
+		}
+		return
+	case "path":
+		// errors are ignored since the path up to the error is rendered.
+		p, _ = parsePath(n.attrs["d"])
+	case "rect":
+		x, y := length("x", vb[2]), length("y", vb[3])
+		w, h := length("width", vb[2]), length("height", vb[3])
+		if w <= 0 || h <= 0 {
+			return
+		}
+		rx, hasRx := n.attrs["rx"]
+		ry, hasRy := n.attrs["ry"]
+		radiusX, radiusY := parseLength(rx, vb[2]), parseLength(ry, vb[3])
+		if !hasRx {
+			radiusX = radiusY
+		} else if !hasRy {
+			radiusY = radiusX
+		}
+		radiusX, radiusY = math.Min(radiusX, w/2), math.Min(radiusY, h/2)
+		if radiusX <= 0 || radiusY <= 0 {
+			p.moveTo(x, y)
+			p.lineTo(x+w, y)
+			p.lineTo(x+w, y+h)
+			p.lineTo(x, y+h)
+		} else {
+			p.moveTo(x+radiusX, y)
+			p.lineTo(x+w-radiusX, y)
+			p.arcTo(x+w-radiusX, y, radiusX, radiusY, 0, false, true, x+w,
+				y+radiusY)
+			p.lineTo(x+w, y+h-radiusY)
+			p.arcTo(x+w, y+h-radiusY, radiusX, radiusY, 0, false, true,
+				x+w-radiusX, y+h)
+			p.lineTo(x+radiusX, y+h)
+			p.arcTo(x+radiusX, y+h, radiusX, radiusY, 0, false, true, x,
+				y+h-radiusY)
+			p.lineTo(x, y+radiusY)
+			p.arcTo(x, y+radiusY, radiusX, radiusY, 0, false, true, x+radiusX, y)
+		}
+		p.close()
+	case "circle":
+		radius := length("r", diag)
+		if radius <= 0 {
+			return
+		}
+		p.ellipse(length("cx", vb[2]), length("cy", vb[3]), radius, radius)
+	case "ellipse":
+		rx, ry := length("rx", vb[2]), length("ry", vb[3])
+		if rx <= 0 || ry <= 0 {
+			return
+		}
+		p.ellipse(length("cx", vb[2]), length("cy", vb[3]), rx, ry)
+	case "line":
+		p.moveTo(length("x1", vb[2]), length("y1", vb[3]))
+		p.lineTo(length("x2", vb[2]), length("y2", vb[3]))
+		// lines are never filled.
+		s.fill = paint{kind: noPaint}
+	case "polyline", "polygon":
+		points := parsePoints(n.attrs["points"])
+		if len(points) < 2 {
+			return
+		}
+		p.moveTo(points[0].x, points[0].y)
+		for _, pt := range points[1:] {
+			p.lineTo(pt.x, pt.y)
+		}
+		if n.name == "polygon" {
+			p.close()
+		}
+	default:
+		// unsupported or non-rendering elements like defs, text and
+		// gradients. symbol elements are only rendered by use.
+		return
+	}
+	if len(p) == 0 {
+		return
+	}
+	r.fill(p, m, s)
+	r.stroke(p, m, s)
+}
+
+// bounds returns the bounding box of the path's points, including control
+// points, in user space as minX, minY, maxX, maxY.
+func (p path) bounds() [4]float64 {
+	ret := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, seg := range p {
+		n := 1
+		if seg.kind == cubicTo {
+			n = 3
+		} else if seg.kind == closePath {
+			n = 0
+		}
+		for _, pt := range seg.pts[:n] {
+			ret[0], ret[1] = math.Min(ret[0], pt.x), math.Min(ret[1], pt.y)
+			ret[2], ret[3] = math.Max(ret[2], pt.x), math.Max(ret[3], pt.y)
+		}
+	}
+	return ret
+}
+
+// source returns the image a shape is drawn with, or nil if it is not drawn.
+func (r *rasterizer) source(pt paint, opacity float64, s style, p path,
+	m matrix) image.Image {
+	opacity *= s.opacity
+	var c color.NRGBA
+	switch pt.kind {
+	case noPaint:
+		return nil
+	case colorPaint:
+		c = pt.color
+	case currentColorPaint:
+		c = s.color
+	case gradientPaint:
+		return r.doc.gradientImage(pt.gradient, p.bounds(), m, opacity)
+	}
+	c.A = uint8(float64(c.A)*opacity + 0.5)
+	if c.A == 0 {
+		return nil
+	}
+	return image.NewUniform(c)
+}
+
+func (r *rasterizer) draw(src image.Image) {
+	r.z.DrawOp = draw.Over
+	r.z.Draw(r.target, r.target.Rect, src, image.Point{})
+	r.z.Reset(r.target.Rect.Dx(), r.target.Rect.Dy())
+}
+
+func (r *rasterizer) fill(p path, m matrix, s style) {
+	src := r.source(s.fill, s.fillOpacity, s, p, m)
+	if src == nil {
+		return
+	}
+	for _, seg := range p {
+		for _, pt := range seg.pts {
+			if !r.check(m.apply(pt.x, pt.y)) {
+				return
+			}
+		}
+	}
+	open := false
+	for _, seg := range p {
+		var pts [3]point
+		for i := range pts {
+			pts[i].x, pts[i].y = m.apply(seg.pts[i].x, seg.pts[i].y)
+		}
+		switch seg.kind {
+		case moveTo:
+			if open {
+				r.z.ClosePath()
+			}
+			r.z.MoveTo(float32(pts[0].x), float32(pts[0].y))
+			open = true
+		case lineTo:
+			r.z.LineTo(float32(pts[0].x), float32(pts[0].y))
+		case cubicTo:
+			r.z.CubeTo(float32(pts[0].x), float32(pts[0].y), float32(pts[1].x),
+				float32(pts[1].y), float32(pts[2].x), float32(pts[2].y))
+		case closePath:
+			r.z.ClosePath()
+			open = false
+		}
+	}
+	if open {
+		r.z.ClosePath()
+	}
+	r.draw(src)
+}
@@ -0,0 +1,105 @@
+package svg
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/QuestScreen/api/render"
+)
+
+func TestRasterizeRejectsHugeCoordinates(t *testing.T) {
+	tests := []string{
+		`<path d="M0 0 h1e30 v1" fill="black"/>`,
+		`<path d="M0 0 h1e30 v1" fill="none" stroke="black"/>`,
+		`<path d="M0 0 h1e308 h1e308 v1" fill="black"/>`,
+		`<rect width="10" height="10" transform="scale(1e30)"/>`,
+		`<line x2="10" y2="10" stroke="black" stroke-width="1e30"/>`,
+	}
+	for _, shape := range tests {
+		doc, err := Parse([]byte(`<svg xmlns="http://www.w3.org/2000/svg" ` +
+			`viewBox="0 0 10 10">` + shape + `</svg>`))
+		if err != nil {
+			t.Fatalf("%s: %v", shape, err)
+		}
+		if _, err := doc.Rasterize(10, 10, render.Exact); err == nil {
+			t.Errorf("%s: expected an error", shape)
+		}
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		x, y     int
+		expected uint8
+	}{
+		{"not drawn directly",
+			`<symbol viewBox="0 0 1 1"><rect width="1" height="1"/></symbol>`,
+			5, 5, 0},
+		{"viewBox scaled to use size",
+			`<symbol id="s" viewBox="0 0 1 1"><rect width="1" height="1"/></symbol>` +
+				`<use href="#s" x="2" y="2" width="4" height="4"/>`, 5, 5, 255},
+		{"outside of use size",
+			`<symbol id="s" viewBox="0 0 1 1"><rect width="1" height="1"/></symbol>` +
+				`<use href="#s" x="2" y="2" width="4" height="4"/>`, 7, 7, 0},
+		{"default size is 100%",
+			`<symbol id="s" viewBox="0 0 1 1"><rect width="1" height="1"/></symbol>` +
+				`<use href="#s"/>`, 9, 9, 255},
+	}
+	for _, tt := range tests {
+		doc, err := Parse([]byte(`<svg xmlns="http://www.w3.org/2000/svg" ` +
+			`viewBox="0 0 10 10">` + tt.body + `</svg>`))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		img, err := doc.Rasterize(10, 10, render.Exact)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if a := img.RGBAAt(tt.x, tt.y).A; a != tt.expected {
+			t.Errorf("%s: alpha at (%d, %d) is %d, want %d", tt.name, tt.x, tt.y,
+				a, tt.expected)
+		}
+	}
+}
+
+func TestRecursiveUse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"circular", `<g id="a"><rect width="1" height="1"/>` +
+			`<use href="#a"/><use href="#a"/></g>`},
+		{"self", `<use id="a" href="#a"/>`},
+		{"root", `<use href="#root"/>`},
+	}
+	for _, tt := range tests {
+		doc, err := Parse([]byte(`<svg xmlns="http://www.w3.org/2000/svg" ` +
+			`id="root" viewBox="0 0 10 10">` + tt.body + `</svg>`))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if _, err := doc.Rasterize(10, 10, render.Exact); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestTooManyElements(t *testing.T) {
+	// every level references the previous one twice, which expands to 2^20
+	// rects without being circular.
+	body := `<rect id="l0" width="1" height="1"/>`
+	for i := 1; i <= 20; i++ {
+		body += fmt.Sprintf(`<g id="l%d"><use href="#l%d"/><use href="#l%d"/></g>`,
+			i, i-1, i-1)
+	}
+	doc, err := Parse([]byte(`<svg xmlns="http://www.w3.org/2000/svg" ` +
+		`viewBox="0 0 10 10"><defs>` + body + `</defs><use href="#l20"/></svg>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.Rasterize(10, 10, render.Exact); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,33 @@
+package render
+
+// TextBlock is a block of text rendered into an image by
+// Renderer.RenderTextBlock.
+type TextBlock struct {
+	// Image contains the rendered text with transparent background.
+	Image Image
+	// Lines is the number of lines the text has been broken into.
+	Lines int
+	// LineHeight is the distance between the baselines of two consecutive
+	// lines in pixels, including line spacing.
+	LineHeight int32
+}
+
+// Draw draws the text block's image into the given area, see Image.Draw.
+func (tb TextBlock) Draw(r Renderer, area Rectangle, alpha uint8) {
+	tb.Image.Draw(r, area, alpha)
+}
+
+// TextMetrics describes the extent of a single line of text as it would be
+// rendered by Renderer.RenderText.
+type TextMetrics struct {
+	// Width and Height of the image RenderText would create.
+	Width, Height int32
+	// Ascent is the distance from the baseline to the upper edge of the image,
+	// Descent the distance from the baseline to the lower edge.
+	// Ascent + Descent equals Height.
+	Ascent, Descent int32
+	// Baseline is the vertical offset of the baseline from the lower edge of
+	// the image, i.e. a Rectangle containing the rendered image has its
+	// baseline at Y + Baseline.
+	Baseline int32
+}
@@ -8,6 +8,9 @@ import (
 // TransitionCurve provides various curves useful for smooth transitions.
 // All its methods return a float between 0.0 and 1.0 calculated from the
 // given elapsed time in relation to the total duration.
+//
+// For transitions consisting of multiple steps or animating multiple values,
+// use the animation package instead.
 type TransitionCurve struct {
 	// Duration sets the length of the transition
 	Duration time.Duration
@@ -23,3 +26,8 @@ func (tc TransitionCurve) Cubic(elapsed time.Duration) float32 {
 	x := float64(elapsed) / float64(tc.Duration)
 	return float32(-2.0*math.Pow(x, 3) + 3.0*math.Pow(x, 2))
 }
+
+// Ease implements a transition curve with the given easing.
+func (tc TransitionCurve) Ease(e Easing, elapsed time.Duration) float32 {
+	return e.At(tc.Linear(elapsed))
+}
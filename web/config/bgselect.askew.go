@@ -39,6 +39,22 @@ func init() {
 		<label for="texture">Texture</label>
 		<!--embed(texture)-->
 	</div>
+	<div class="qs-config-item-fragment">
+		<label for="gradient-kind">Gradient</label>
+		<select name="gradient-kind">
+			<option value="0">None</option>
+			<option value="1">Linear</option>
+			<option value="2">Radial</option>
+		</select>
+	</div>
+	<div class="qs-config-item-fragment">
+		<label for="gradient-angle">Angle</label>
+		<input type="number" name="gradient-angle" min="-360" max="360" step="1" required=""/>
+	</div>
+	<!--embed(stops)-->
+	<div class="qs-config-item-fragment">
+		<button class="pure-button">Add stop</button>
+	</div>
 `)
 }
 
@@ -53,8 +69,14 @@ type BackgroundSelect struct {
 	poDisabled       askew.BoolValue
 	secondaryOpacity askew.IntValue
 	soDisabled       askew.BoolValue
+	gradientKind     askew.IntValue
+	gkDisabled       askew.BoolValue
+	gradientAngle    askew.StringValue
+	gaDisabled       askew.BoolValue
+	addDisabled      askew.BoolValue
 	data             api.Background
 	editHandler      EditHandler
+	stops            gradientStopList
 	texture          controls.Dropdown
 }
 
@@ -78,6 +100,11 @@ func (o *BackgroundSelect) askewInit() {
 	o.poDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 5, 3, 3, 3, 0)
 	o.secondaryOpacity.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 5, 3, 3, 5, 0)
 	o.soDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 5, 3, 3, 5, 0)
+	o.gradientKind.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 9, 3)
+	o.gkDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 9, 3)
+	o.gradientAngle.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 11, 3)
+	o.gaDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 11, 3)
+	o.addDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 15, 1)
 	{
 		src := o.αcd.Walk(5, 3, 1, 3, 0)
 		{
@@ -122,6 +149,46 @@ func (o *BackgroundSelect) askewInit() {
 			src.Call("addEventListener", "input", wrapper)
 		}
 	}
+	{
+		src := o.αcd.Walk(9, 3)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(11, 3)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(15, 1)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.addStop()
+				arguments[0].Call("preventDefault")
+				return nil
+			})
+			src.Call("addEventListener", "click", wrapper)
+		}
+	}
+	{
+		container := o.αcd.Walk()
+		o.stops.Init(container, 13)
+		o.stops.DefaultController = o
+
+	}
 	{
 		container := o.αcd.Walk(7)
 		o.texture.Init(controls.SelectAtMostOne, controls.SelectionIndicator, ``)
@@ -136,21 +203,168 @@ func (o *BackgroundSelect) askewInit() {
 // The component will be inserted in front of 'before', or at the end if 'before' is 'js.Undefined()'.
 func (o *BackgroundSelect) InsertInto(parent js.Value, before js.Value) {
 	o.αcd.DoInsert(parent, before)
+	o.stops.αmgr.UpdateParent(o.αcd.DocumentFragment(), parent, before)
 }
 
 // Extract removes this component from its current parent.
 // The component will be in initial state afterwards.
 func (o *BackgroundSelect) Extract() {
 	o.αcd.DoExtract()
+	o.stops.αmgr.UpdateParent(o.αcd.First().Get("parentNode"), o.αcd.DocumentFragment(), js.Undefined())
 }
 
 // Destroy destroys this element (and all contained components). If it is
 // currently inserted anywhere, it gets removed before.
 func (o *BackgroundSelect) Destroy() {
+	o.stops.DestroyAll()
 	o.texture.Destroy()
 	o.αcd.DoDestroy()
 }
 
+// gradientStopController can be implemented to handle external events
+// generated by gradientStop
+type gradientStopController interface {
+	edited()
+	removeStop(index int)
+}
+
+var αgradientStopTemplate = js.Global().Get("document").Call("createElement", "template")
+
+func init() {
+	αgradientStopTemplate.Set("innerHTML", `
+	<!--controller-->
+	<div class="qs-config-item-fragment">
+		<label>Stop</label>
+		<input type="number" name="stop-offset" min="0" max="1" step="0.05" required=""/>
+		<input type="color" name="stop-color" required=""/>
+		<input type="range" name="stop-opacity" min="0" max="255" step="1" required=""/>
+		<button class="pure-button"><i class="fas fa-trash"></i></button>
+	</div>
+`)
+}
+
+// gradientStop is a DOM component autogenerated by Askew
+type gradientStop struct {
+	αcd askew.ComponentData
+	// Controller is the adapter for events generated from this component.
+	// if nil, events that would be passed to the controller will not be handled.
+	Controller      gradientStopController
+	offset          askew.StringValue
+	offsetDisabled  askew.BoolValue
+	color           askew.StringValue
+	colorDisabled   askew.BoolValue
+	opacity         askew.IntValue
+	opacityDisabled askew.BoolValue
+	removeDisabled  askew.BoolValue
+}
+
+// newGradientStop creates a new component and initializes it with the given parameters.
+func newGradientStop(index int) *gradientStop {
+	ret := new(gradientStop)
+	ret.askewInit(index)
+	return ret
+}
+
+// Init initializes the component with the given arguments.
+func (o *gradientStop) Init(index int) {
+	o.askewInit(index)
+}
+
+// FirstNode returns the first DOM node of this component.
+// It implements the askew.Component interface.
+func (o *gradientStop) FirstNode() js.Value {
+	return o.αcd.First()
+}
+
+// askewInit initializes the component, discarding all previous information.
+// The component is initially a DocumentFragment until it gets inserted into
+// the main document. It can be manipulated both before and after insertion.
+func (o *gradientStop) askewInit(index int) {
+	o.αcd.Init(αgradientStopTemplate.Get("content").Call("cloneNode", true))
+
+	o.offset.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 3, 3)
+	o.offsetDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 3, 3)
+	o.color.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 3, 5)
+	o.colorDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 3, 5)
+	o.opacity.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 3, 7)
+	o.opacityDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 3, 7)
+	o.removeDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 3, 9)
+	{
+		block := o.αcd.Walk()
+		{
+			tmp := askew.BoundDatasetAt(
+				askew.WalkPath(block, 3, 9), "index")
+			askew.Assign(tmp, index)
+		}
+	}
+	{
+		src := o.αcd.Walk(3, 3)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.Controller.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(3, 5)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.Controller.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(3, 7)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.Controller.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(3, 9)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+				self := arguments[0].Get("currentTarget")
+
+				go o.Controller.removeStop((&askew.IntValue{BoundValue: askew.BoundDatasetAt(self, "index")}).Get())
+				arguments[0].Call("preventDefault")
+				return nil
+			})
+			src.Call("addEventListener", "click", wrapper)
+		}
+	}
+}
+
+// InsertInto inserts this component into the given object.
+// The component will be in inserted state afterwards.
+//
+// The component will be inserted in front of 'before', or at the end if 'before' is 'js.Undefined()'.
+func (o *gradientStop) InsertInto(parent js.Value, before js.Value) {
+	o.αcd.DoInsert(parent, before)
+}
+
+// Extract removes this component from its current parent.
+// The component will be in initial state afterwards.
+func (o *gradientStop) Extract() {
+	o.αcd.DoExtract()
+}
+
+// Destroy destroys this element (and all contained components). If it is
+// currently inserted anywhere, it gets removed before.
+func (o *gradientStop) Destroy() {
+	o.αcd.DoDestroy()
+}
+
 // BackgroundSelectList is a list of BackgroundSelect whose manipulation methods auto-update
 // the corresponding nodes in the document.
 type BackgroundSelectList struct {
@@ -227,6 +441,85 @@ func (l *BackgroundSelectList) DestroyAll() {
 	l.αitems = l.αitems[:0]
 }
 
+// gradientStopList is a list of gradientStop whose manipulation methods auto-update
+// the corresponding nodes in the document.
+type gradientStopList struct {
+	αmgr              askew.ListManager
+	αitems            []*gradientStop
+	DefaultController gradientStopController
+}
+
+// Init initializes the list, discarding previous data.
+// The list's items will be placed in the given container, starting at the
+// given index.
+func (l *gradientStopList) Init(container js.Value, index int) {
+	l.αmgr = askew.CreateListManager(container, index)
+	l.αitems = nil
+}
+
+// Len returns the number of items in the list.
+func (l *gradientStopList) Len() int {
+	return len(l.αitems)
+}
+
+// Item returns the item at the current index.
+func (l *gradientStopList) Item(index int) *gradientStop {
+	return l.αitems[index]
+}
+
+// Append appends the given item to the list.
+func (l *gradientStopList) Append(item *gradientStop) {
+	if item == nil {
+		panic("cannot append nil to list")
+	}
+	l.αmgr.Append(item)
+	l.αitems = append(l.αitems, item)
+	item.Controller = l.DefaultController
+	return
+}
+
+// Insert inserts the given item at the given index into the list.
+func (l *gradientStopList) Insert(index int, item *gradientStop) {
+	var prev js.Value
+	if index < len(l.αitems) {
+		prev = l.αitems[index].αcd.First()
+	}
+	if item == nil {
+		panic("cannot insert nil into list")
+	}
+	l.αmgr.Insert(item, prev)
+	l.αitems = append(l.αitems, nil)
+	copy(l.αitems[index+1:], l.αitems[index:])
+	l.αitems[index] = item
+	item.Controller = l.DefaultController
+	return
+}
+
+// Remove removes the item at the given index from the list and returns it.
+func (l *gradientStopList) Remove(index int) *gradientStop {
+	item := l.αitems[index]
+	item.Extract()
+	copy(l.αitems[index:], l.αitems[index+1:])
+	l.αitems = l.αitems[:len(l.αitems)-1]
+	return item
+}
+
+// Destroy destroys the item at the given index and removes it from the list.
+func (l *gradientStopList) Destroy(index int) {
+	item := l.αitems[index]
+	item.Destroy()
+	copy(l.αitems[index:], l.αitems[index+1:])
+	l.αitems = l.αitems[:len(l.αitems)-1]
+}
+
+// DestroyAll destroys all items in the list and empties it.
+func (l *gradientStopList) DestroyAll() {
+	for _, item := range l.αitems {
+		item.Destroy()
+	}
+	l.αitems = l.αitems[:0]
+}
+
 // OptionalBackgroundSelect is a nillable embeddable container for BackgroundSelect.
 type OptionalBackgroundSelect struct {
 	αcur *BackgroundSelect
@@ -269,3 +562,48 @@ func (o *OptionalBackgroundSelect) Remove() askew.Component {
 	}
 	return nil
 }
+
+// OptionalgradientStop is a nillable embeddable container for gradientStop.
+type OptionalgradientStop struct {
+	αcur              *gradientStop
+	αmgr              askew.ListManager
+	DefaultController gradientStopController
+}
+
+// Init initializes the container to be empty.
+// The contained item, if any, will be placed in the given container at the
+// given index.
+func (o *OptionalgradientStop) Init(container js.Value, index int) {
+	o.αmgr = askew.CreateListManager(container, index)
+	o.αcur = nil
+}
+
+// Item returns the current item, or nil if no item is assigned
+func (o *OptionalgradientStop) Item() *gradientStop {
+	return o.αcur
+}
+
+// Set sets the contained item destroying the current one.
+// Give nil as value to simply destroy the current item.
+func (o *OptionalgradientStop) Set(value *gradientStop) {
+	if o.αcur != nil {
+		o.αcur.Destroy()
+	}
+	o.αcur = value
+	if value != nil {
+		o.αmgr.Append(value)
+		value.Controller = o.DefaultController
+	}
+}
+
+// Remove removes the current item and returns it.
+// Returns nil if there is no current item.
+func (o *OptionalgradientStop) Remove() askew.Component {
+	if o.αcur != nil {
+		ret := o.αcur
+		ret.Extract()
+		o.αcur = nil
+		return ret
+	}
+	return nil
+}
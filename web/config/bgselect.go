@@ -45,6 +45,42 @@ func (bg *BackgroundSelect) Reset() {
 	bg.secondaryColor.Set(bg.data.Secondary.WithoutAlpha().HexRepr())
 	bg.secondaryOpacity.Set(int(bg.data.Secondary.A))
 	bg.texture.SetItem(bg.data.TextureIndex, true)
+	bg.gradientKind.Set(int(bg.data.Gradient.Kind))
+	bg.gradientAngle.Set(formatNumber(bg.data.Gradient.Angle))
+	bg.setStops(bg.data.Gradient.UsedStops())
+}
+
+// setStops replaces the displayed gradient stops with the given ones.
+func (bg *BackgroundSelect) setStops(stops []api.GradientStop) {
+	bg.stops.DestroyAll()
+	disabled := bg.gkDisabled.Get()
+	for i, stop := range stops {
+		item := newGradientStop(i)
+		item.offset.Set(formatNumber(stop.Offset))
+		item.color.Set(stop.Color.WithoutAlpha().HexRepr())
+		item.opacity.Set(int(stop.Color.A))
+		item.setDisabled(disabled)
+		bg.stops.Append(item)
+	}
+	bg.addDisabled.Set(disabled || len(stops) >= api.MaxGradientStops)
+}
+
+// currentStops returns the gradient stops currently entered in the UI.
+func (bg *BackgroundSelect) currentStops() []api.GradientStop {
+	ret := make([]api.GradientStop, bg.stops.Len())
+	for i := range ret {
+		item := bg.stops.Item(i)
+		ret[i] = api.GradientStop{Offset: parseNumber(item.offset.Get()),
+			Color: parseColor(item.color.Get(), item.opacity.Get())}
+	}
+	return ret
+}
+
+func (gs *gradientStop) setDisabled(value bool) {
+	gs.offsetDisabled.Set(value)
+	gs.colorDisabled.Set(value)
+	gs.opacityDisabled.Set(value)
+	gs.removeDisabled.Set(value)
 }
 
 // SetEnabled enables or disables the GUI.
@@ -54,6 +90,12 @@ func (bg *BackgroundSelect) SetEnabled(value bool) {
 	bg.scDisabled.Set(!value)
 	bg.soDisabled.Set(!value)
 	bg.texture.Disabled.Set(!value)
+	bg.gkDisabled.Set(!value)
+	bg.gaDisabled.Set(!value)
+	for i := 0; i < bg.stops.Len(); i++ {
+		bg.stops.Item(i).setDisabled(!value)
+	}
+	bg.addDisabled.Set(!value || bg.stops.Len() >= api.MaxGradientStops)
 }
 
 // Send returns an instance of api.Background
@@ -70,6 +112,11 @@ func (bg *BackgroundSelect) Send(ctx server.Context) interface{} {
 	bg.data.Secondary = tmp.WithAlpha(uint8(bg.secondaryOpacity.Get()))
 
 	bg.data.TextureIndex = bg.texture.CurIndex
+	bg.data.Gradient = api.Gradient{Kind: api.GradientKind(bg.gradientKind.Get()),
+		Angle: parseNumber(bg.gradientAngle.Get())}
+	if err := bg.data.Gradient.SetStops(bg.currentStops()...); err != nil {
+		panic(err)
+	}
 	return &bg.data
 }
 
@@ -77,6 +124,22 @@ func (bg *BackgroundSelect) edited() {
 	bg.editHandler.Edited()
 }
 
+func (bg *BackgroundSelect) addStop() {
+	stops := bg.currentStops()
+	if len(stops) >= api.MaxGradientStops {
+		return
+	}
+	bg.setStops(append(stops, api.GradientStop{Offset: 1,
+		Color: api.RGBA{R: 255, G: 255, B: 255, A: 255}}))
+	bg.editHandler.Edited()
+}
+
+func (bg *BackgroundSelect) removeStop(index int) {
+	stops := bg.currentStops()
+	bg.setStops(append(stops[:index], stops[index+1:]...))
+	bg.editHandler.Edited()
+}
+
 func (bg *BackgroundSelect) ItemClicked(index int) bool {
 	bg.editHandler.Edited()
 	return true
@@ -43,6 +43,34 @@ func init() {
 		<label for="font-color">Color</label>
 		<input type="color" name="font-color" required=""/>
 	</div>
+	<table class="qs-config-item-table">
+		<thead>
+			<tr>
+				<th></th><th>Outline</th><th>Shadow</th><th>Glow</th>
+			</tr>
+		</thead>
+		<tbody>
+			<tr>
+				<th>Color</th>
+				<td><input type="color" name="outline-color" required=""/></td>
+				<td><input type="color" name="shadow-color" required=""/></td>
+				<td><input type="color" name="glow-color" required=""/></td>
+			</tr>
+			<tr>
+				<th>Opacity</th>
+				<td><input type="range" name="outline-opacity" min="0" max="255" step="1" required=""/></td>
+				<td><input type="range" name="shadow-opacity" min="0" max="255" step="1" required=""/></td>
+				<td><input type="range" name="glow-opacity" min="0" max="255" step="1" required=""/></td>
+			</tr>
+			<tr>
+				<th>Size</th>
+				<td><input type="number" name="outline-width" style="width: 4em" min="0" max="2" step="0.05" required=""/></td>
+				<td><input type="number" name="shadow-offset-x" style="width: 4em" min="-4" max="4" step="0.1" required=""/>
+					<input type="number" name="shadow-offset-y" style="width: 4em" min="-4" max="4" step="0.1" required=""/></td>
+				<td><input type="number" name="glow-radius" style="width: 4em" min="0" max="4" step="0.1" required=""/></td>
+			</tr>
+		</tbody>
+	</table>
 `)
 }
 
@@ -59,6 +87,26 @@ type FontSelect struct {
 	italicDisabled   askew.BoolValue
 	color            askew.StringValue
 	colorDisabled    askew.BoolValue
+	outlineColor     askew.StringValue
+	ocDisabled       askew.BoolValue
+	shadowColor      askew.StringValue
+	scDisabled       askew.BoolValue
+	glowColor        askew.StringValue
+	gcDisabled       askew.BoolValue
+	outlineOpacity   askew.IntValue
+	ooDisabled       askew.BoolValue
+	shadowOpacity    askew.IntValue
+	soDisabled       askew.BoolValue
+	glowOpacity      askew.IntValue
+	goDisabled       askew.BoolValue
+	outlineWidth     askew.StringValue
+	owDisabled       askew.BoolValue
+	shadowX          askew.StringValue
+	sxDisabled       askew.BoolValue
+	shadowY          askew.StringValue
+	syDisabled       askew.BoolValue
+	glowRadius       askew.StringValue
+	grDisabled       askew.BoolValue
 	data             api.Font
 	editHandler      EditHandler
 }
@@ -85,6 +133,26 @@ func (o *FontSelect) askewInit(families []string) {
 	o.italicDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 9, 3, 3)
 	o.color.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 11, 3)
 	o.colorDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 11, 3)
+	o.outlineColor.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 1, 3, 0)
+	o.ocDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 1, 3, 0)
+	o.shadowColor.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 1, 5, 0)
+	o.scDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 1, 5, 0)
+	o.glowColor.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 1, 7, 0)
+	o.gcDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 1, 7, 0)
+	o.outlineOpacity.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 3, 3, 0)
+	o.ooDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 3, 3, 0)
+	o.shadowOpacity.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 3, 5, 0)
+	o.soDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 3, 5, 0)
+	o.glowOpacity.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 3, 7, 0)
+	o.goDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 3, 7, 0)
+	o.outlineWidth.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 5, 3, 0)
+	o.owDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 5, 3, 0)
+	o.shadowX.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 5, 5, 0)
+	o.sxDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 5, 5, 0)
+	o.shadowY.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 5, 5, 2)
+	o.syDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 5, 5, 2)
+	o.glowRadius.BoundValue = askew.NewBoundProperty(&o.αcd, "value", 13, 3, 5, 7, 0)
+	o.grDisabled.BoundValue = askew.NewBoundProperty(&o.αcd, "disabled", 13, 3, 5, 7, 0)
 	{
 		block := o.αcd.Walk()
 		{
@@ -166,6 +234,116 @@ func (o *FontSelect) askewInit(families []string) {
 			src.Call("addEventListener", "input", wrapper)
 		}
 	}
+	{
+		src := o.αcd.Walk(13, 3, 1, 3, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 1, 5, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 1, 7, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 3, 3, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 3, 5, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 3, 7, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 5, 3, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 5, 5, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 5, 5, 2)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
+	{
+		src := o.αcd.Walk(13, 3, 5, 7, 0)
+		{
+			wrapper := js.FuncOf(func(this js.Value, arguments []js.Value) interface{} {
+
+				go o.edited()
+				return nil
+			})
+			src.Call("addEventListener", "input", wrapper)
+		}
+	}
 }
 
 // InsertInto inserts this component into the given object.
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/QuestScreen/api"
 	"github.com/QuestScreen/api/server"
@@ -45,6 +46,38 @@ func (fs *FontSelect) Reset() {
 	fs.bold.Set(fs.data.Style == api.BoldFont || fs.data.Style == api.BoldItalicFont)
 	fs.italic.Set(fs.data.Style == api.ItalicFont || fs.data.Style == api.BoldItalicFont)
 	fs.color.Set(fs.data.Color.WithoutAlpha().HexRepr())
+	fs.outlineColor.Set(fs.data.Outline.Color.WithoutAlpha().HexRepr())
+	fs.outlineOpacity.Set(int(fs.data.Outline.Color.A))
+	fs.outlineWidth.Set(formatNumber(fs.data.Outline.Width))
+	fs.shadowColor.Set(fs.data.Shadow.Color.WithoutAlpha().HexRepr())
+	fs.shadowOpacity.Set(int(fs.data.Shadow.Color.A))
+	fs.shadowX.Set(formatNumber(fs.data.Shadow.OffsetX))
+	fs.shadowY.Set(formatNumber(fs.data.Shadow.OffsetY))
+	fs.glowColor.Set(fs.data.Glow.Color.WithoutAlpha().HexRepr())
+	fs.glowOpacity.Set(int(fs.data.Glow.Color.A))
+	fs.glowRadius.Set(formatNumber(fs.data.Glow.Radius))
+}
+
+// formatNumber formats the value of a number input.
+func formatNumber(value float32) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 32)
+}
+
+// parseNumber parses the value of a number input. Invalid input yields 0.
+func parseNumber(input string) float32 {
+	value, err := strconv.ParseFloat(input, 32)
+	if err != nil {
+		return 0
+	}
+	return float32(value)
+}
+
+func parseColor(hex string, alpha int) api.RGBA {
+	var tmp api.RGB
+	if err := tmp.FromHexRepr(hex); err != nil {
+		panic(err)
+	}
+	return tmp.WithAlpha(uint8(alpha))
 }
 
 // SetEnabled enables or disables the GUI.
@@ -54,6 +87,16 @@ func (fs *FontSelect) SetEnabled(value bool) {
 	fs.boldDisabled.Set(!value)
 	fs.italicDisabled.Set(!value)
 	fs.colorDisabled.Set(!value)
+	fs.ocDisabled.Set(!value)
+	fs.ooDisabled.Set(!value)
+	fs.owDisabled.Set(!value)
+	fs.scDisabled.Set(!value)
+	fs.soDisabled.Set(!value)
+	fs.sxDisabled.Set(!value)
+	fs.syDisabled.Set(!value)
+	fs.gcDisabled.Set(!value)
+	fs.goDisabled.Set(!value)
+	fs.grDisabled.Set(!value)
 }
 
 // Send returns an api.Font object containing the currently selected values.
@@ -67,11 +110,14 @@ func (fs *FontSelect) Send(ctx server.Context) interface{} {
 	if fs.italic.Get() {
 		fs.data.Style += 2
 	}
-	var tmp api.RGB
-	if err := tmp.FromHexRepr(fs.color.Get()); err != nil {
-		panic(err)
-	}
-	fs.data.Color = tmp.WithAlpha(255)
+	fs.data.Color = parseColor(fs.color.Get(), 255)
+	fs.data.Outline = api.TextOutline{Width: parseNumber(fs.outlineWidth.Get()),
+		Color: parseColor(fs.outlineColor.Get(), fs.outlineOpacity.Get())}
+	fs.data.Shadow = api.TextShadow{OffsetX: parseNumber(fs.shadowX.Get()),
+		OffsetY: parseNumber(fs.shadowY.Get()),
+		Color:   parseColor(fs.shadowColor.Get(), fs.shadowOpacity.Get())}
+	fs.data.Glow = api.TextGlow{Radius: parseNumber(fs.glowRadius.Get()),
+		Color: parseColor(fs.glowColor.Get(), fs.glowOpacity.Get())}
 	return &fs.data
 }
 